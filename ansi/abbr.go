@@ -0,0 +1,87 @@
+package ansi
+
+import (
+	"slices"
+	"strings"
+
+	"charm.land/glamour/v2/internal/abbr"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// AbbreviationMode determines how the expansions of abbreviations get shown.
+type AbbreviationMode int
+
+const (
+	// AbbreviationsMarked only marks abbreviations.
+	AbbreviationsMarked AbbreviationMode = iota
+	// AbbreviationsExpanded follows the first occurrence of every
+	// abbreviation with its expansion.
+	AbbreviationsExpanded
+	// AbbreviationsGlossary lists the abbreviations used in a document and
+	// their expansions at the end of the document.
+	AbbreviationsGlossary
+)
+
+// abbreviations holds the abbreviations used in the current document, in
+// the order they're first used in.
+type abbreviations struct {
+	used []*abbr.Abbreviation
+}
+
+// use records an occurrence of an abbreviation and reports whether it's the
+// first one.
+func (a *abbreviations) use(n *abbr.Abbreviation) bool {
+	if slices.ContainsFunc(a.used, func(u *abbr.Abbreviation) bool { return u.Term == n.Term }) {
+		return false
+	}
+	a.used = append(a.used, n)
+	return true
+}
+
+// newAbbreviationElement returns the element rendering an abbreviation.
+func (tr *ANSIRenderer) newAbbreviationElement(n *abbr.Abbreviation, source []byte) ElementRenderer {
+	ctx := tr.context
+
+	var children []ElementRenderer
+	for nn := n.FirstChild(); nn != nil; nn = nn.NextSibling() {
+		children = append(children, tr.NewElement(nn, source).Renderer)
+	}
+	e := &StyledElement{Children: children, Style: ctx.options.Styles.Abbreviation}
+
+	first := ctx.abbreviations.use(n)
+	if ctx.options.Abbreviations != AbbreviationsExpanded || !first || n.Expansion == "" {
+		return e
+	}
+	return &StyledElement{Children: []ElementRenderer{
+		e,
+		&BaseElement{Token: " (" + n.Expansion + ")", Style: ctx.options.Styles.Text},
+	}}
+}
+
+// printGlossary lists the abbreviations used in the document, sorted by
+// term, along with their expansions.
+func (r *ANSIRenderer) printGlossary() {
+	ctx := r.context
+	if ctx.options.Abbreviations != AbbreviationsGlossary || len(ctx.abbreviations.used) == 0 {
+		return
+	}
+	used := slices.Clone(ctx.abbreviations.used)
+	slices.SortFunc(used, func(a, b *abbr.Abbreviation) int {
+		return strings.Compare(strings.ToLower(a.Term), strings.ToLower(b.Term))
+	})
+
+	var width int
+	for _, a := range used {
+		width = max(width, xansi.StringWidth(a.Term))
+	}
+
+	w := ctx.blockStack.Current().Block
+	style := ctx.blockStack.Current().Style.StylePrimitive
+	for _, a := range used {
+		_, _ = ctx.renderText(w, style, "\n")
+		_, _ = ctx.renderText(w, cascadeStylePrimitives(style, ctx.options.Styles.Abbreviation), a.Term)
+		padding := strings.Repeat(" ", width-xansi.StringWidth(a.Term)+2)
+		_, _ = ctx.renderText(w, cascadeStylePrimitives(style, ctx.options.Styles.Text), padding+a.Expansion)
+	}
+	_, _ = ctx.renderText(w, style, "\n")
+}
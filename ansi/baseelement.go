@@ -36,7 +36,22 @@ func formatToken(format string, token string) (string, error) {
 	return b.String(), err
 }
 
+// underlineStyles maps the names of underline styles to their attributes.
+var underlineStyles = map[string]ansi.Underline{
+	"single": ansi.UnderlineSingle,
+	"double": ansi.UnderlineDouble,
+	"curly":  ansi.UnderlineCurly,
+	"dotted": ansi.UnderlineDotted,
+	"dashed": ansi.UnderlineDashed,
+}
+
 func renderText(w io.Writer, rules StylePrimitive, s string) (int, error) { //nolint:unparam
+	return renderMarkedText(w, rules, s, "")
+}
+
+// renderMarkedText renders text like renderText, marking its graphemes as
+// belonging to a span unless marker is empty.
+func renderMarkedText(w io.Writer, rules StylePrimitive, s, marker string) (int, error) {
 	if len(s) == 0 {
 		return 0, nil
 	}
@@ -61,7 +76,11 @@ func renderText(w io.Writer, rules StylePrimitive, s string) (int, error) { //no
 		style = style.BackgroundColor(lipgloss.Color(*rules.BackgroundColor))
 	}
 	if rules.Underline != nil && *rules.Underline {
-		style = style.Underline(true)
+		u, ok := underlineStyles[rules.UnderlineStyle]
+		if !ok {
+			u = ansi.UnderlineSingle
+		}
+		style = style.UnderlineStyle(u)
 	}
 	if rules.Bold != nil && *rules.Bold {
 		style = style.Bold()
@@ -79,6 +98,9 @@ func renderText(w io.Writer, rules StylePrimitive, s string) (int, error) { //no
 		style = style.Blink(true)
 	}
 
+	if marker != "" {
+		s = markGraphemes(s, marker)
+	}
 	n, err := io.WriteString(w, style.Styled(s))
 	if err != nil {
 		return n, fmt.Errorf("glamour: error writing to writer: %w", err)
@@ -93,7 +115,7 @@ func (e *BaseElement) StyleOverrideRender(w io.Writer, ctx RenderContext, style
 	st1 := cascadeStylePrimitives(bs.Current().Style.StylePrimitive, style)
 	st2 := cascadeStylePrimitives(bs.With(e.Style), style)
 
-	return e.doRender(w, st1, st2)
+	return e.doRender(w, ctx, st1, st2)
 }
 
 // Render renders a BaseElement.
@@ -101,25 +123,25 @@ func (e *BaseElement) Render(w io.Writer, ctx RenderContext) error {
 	bs := ctx.blockStack
 	st1 := bs.Current().Style.StylePrimitive
 	st2 := bs.With(e.Style)
-	return e.doRender(w, st1, st2)
+	return e.doRender(w, ctx, st1, st2)
 }
 
-func (e *BaseElement) doRender(w io.Writer, st1, st2 StylePrimitive) error {
-	_, _ = renderText(w, st1, e.Prefix)
+func (e *BaseElement) doRender(w io.Writer, ctx RenderContext, st1, st2 StylePrimitive) error {
+	_, _ = ctx.renderText(w, st1, e.Prefix)
 	defer func() {
-		_, _ = renderText(w, st1, e.Suffix)
+		_, _ = ctx.renderText(w, st1, e.Suffix)
 	}()
 
 	// render unstyled prefix/suffix
-	_, _ = renderText(w, st1, st2.BlockPrefix)
+	_, _ = ctx.renderText(w, st1, st2.BlockPrefix)
 	defer func() {
-		_, _ = renderText(w, st1, st2.BlockSuffix)
+		_, _ = ctx.renderText(w, st1, st2.BlockSuffix)
 	}()
 
 	// render styled prefix/suffix
-	_, _ = renderText(w, st2, st2.Prefix)
+	_, _ = ctx.renderText(w, st2, st2.Prefix)
 	defer func() {
-		_, _ = renderText(w, st2, st2.Suffix)
+		_, _ = ctx.renderText(w, st2, st2.Suffix)
 	}()
 
 	s := e.Token
@@ -130,7 +152,7 @@ func (e *BaseElement) doRender(w io.Writer, st1, st2 StylePrimitive) error {
 			return err
 		}
 	}
-	_, _ = renderText(w, st2, escapeReplacer.Replace(s))
+	_, _ = ctx.renderText(w, st2, escapeReplacer.Replace(s))
 	return nil
 }
 
@@ -0,0 +1,383 @@
+package ansi
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	xansi "github.com/charmbracelet/x/ansi"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// BidiMode determines how bidirectional text, like Arabic and Hebrew, gets
+// rendered.
+type BidiMode int
+
+const (
+	// BidiNone renders text in logical order.
+	BidiNone BidiMode = iota
+	// BidiVisual reorders the wrapped lines of paragraphs and headings with
+	// the Unicode Bidirectional Algorithm and right-aligns right-to-left ones,
+	// for terminals without bidi support. Table cells are laid out by Lip
+	// Gloss and keep their logical order.
+	BidiVisual
+	// BidiTerminal marks right-to-left paragraphs and headings with control
+	// sequences setting the character path, for terminals reordering text
+	// themselves.
+	BidiTerminal
+)
+
+const (
+	// lri and pdi enclose code spans, so they're laid out as a unit.
+	lri = '\u2066'
+	pdi = '\u2069'
+	// lrm marks the start of lines of left-to-right paragraphs.
+	lrm = '\u200e'
+
+	// scpRTL and scpDefault select the character path of the following
+	// lines.
+	scpRTL     = "\x1b[2 k"
+	scpDefault = "\x1b[0 k"
+
+	hyperlinkReset = "\x1b]8;;\x07"
+)
+
+// bidiState is set while a paragraph gets rendered.
+type bidiState struct {
+	paragraph bool
+}
+
+// isolateCode reports whether code spans get enclosed by isolates.
+func (ctx RenderContext) isolateCode() bool {
+	switch ctx.options.Bidi {
+	case BidiTerminal:
+		return true
+	case BidiVisual:
+		return ctx.bidi.paragraph
+	}
+	return false
+}
+
+// bidiWidth returns the width right-to-left lines get aligned to: the
+// rendering width, less what indentation tokens like quote prefixes take up
+// beyond the indentation they stand for.
+func (ctx RenderContext) bidiWidth() int {
+	width := int(ctx.blockStack.Width(ctx)) //nolint: gosec
+	for _, b := range *ctx.blockStack {
+		if b.Style.Indent != nil && b.Style.IndentToken != nil {
+			width -= int(*b.Style.Indent) * (xansi.StringWidth(*b.Style.IndentToken) - 1) //nolint: gosec
+		}
+	}
+	return max(width, 0)
+}
+
+// bidiParagraph lays out the wrapped lines of a paragraph according to the
+// bidi mode, right-aligning right-to-left lines to width.
+func (ctx RenderContext) bidiParagraph(flow string, width int, style StylePrimitive) string {
+	if ctx.options.Bidi == BidiNone {
+		return flow
+	}
+	rtl := isRTL(flow)
+	if ctx.options.Bidi == BidiTerminal {
+		if rtl {
+			return scpRTL + flow + scpDefault
+		}
+		return flow
+	}
+
+	lines := strings.Split(flow, "\n")
+	code := false
+	for i, line := range lines {
+		var cells []bidiCell
+		cells, code = parseBidiLine(line, code)
+		line = reorder(line, cells, rtl)
+		if rtl {
+			var b strings.Builder
+			_, _ = ctx.renderText(&b, style, strings.Repeat(" ", max(width-xansi.StringWidth(line), 0)))
+			line = b.String() + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isRTL reports whether the first strong character of a paragraph outside of
+// code spans is right-to-left.
+func isRTL(s string) bool {
+	code := false
+	for _, r := range xansi.Strip(s) {
+		switch r {
+		case lri:
+			code = true
+			continue
+		case pdi:
+			code = false
+			continue
+		}
+		if code {
+			continue
+		}
+		p, _ := bidi.LookupRune(r)
+		switch p.Class() {
+		case bidi.L:
+			return false
+		case bidi.R, bidi.AL:
+			return true
+		}
+	}
+	return false
+}
+
+// A bidiCell is a character of a rendered line along with the escape
+// sequences styling it.
+type bidiCell struct {
+	r      rune
+	sgr    string
+	link   string
+	marker string
+	code   bool
+}
+
+// parseBidiLine splits a rendered line into cells. Isolates are dropped;
+// code tells whether the line starts inside a code span, and the returned
+// value whether it ends inside one.
+func parseBidiLine(line string, code bool) ([]bidiCell, bool) {
+	var cells []bidiCell
+	var sgr, link, marker string
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' {
+			seq := escapeSequence(line[i:])
+			switch {
+			case strings.HasPrefix(seq, spanMarker):
+				marker = seq
+			case seq == "\x1b[m" || seq == "\x1b[0m":
+				sgr = ""
+			case strings.HasPrefix(seq, "\x1b]8;"):
+				link = seq
+				if strings.HasPrefix(seq, "\x1b]8;;") {
+					link = ""
+				}
+			case strings.HasPrefix(seq, "\x1b["):
+				sgr += seq
+			}
+			i += len(seq)
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+		switch r {
+		case lri:
+			code = true
+		case pdi:
+			code = false
+		default:
+			cells = append(cells, bidiCell{r: r, sgr: sgr, link: link, marker: marker, code: code})
+		}
+		marker = ""
+	}
+	return cells, code
+}
+
+// escapeSequence returns the CSI or OSC sequence s starts with.
+func escapeSequence(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return s[:i+1]
+			}
+		}
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return s[:i+1]
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return s[:i+2]
+			}
+		}
+	default:
+		return s[:2]
+	}
+	return s
+}
+
+// isolates removes the isolates enclosing code spans.
+var isolates = strings.NewReplacer(string(lri), "", string(pdi), "")
+
+// A bidiRun is a run of cells of the same direction.
+type bidiRun struct {
+	cells []bidiCell
+	rtl   bool
+	// numbers is the number of cells at the start of a left-to-right run
+	// holding a number. Following a right-to-left run, they're embedded in
+	// it.
+	numbers int
+}
+
+// reorder lays out a line in visual order. Code spans are treated as
+// left-to-right text. Lines without right-to-left text are kept as they are.
+func reorder(line string, cells []bidiCell, rtl bool) string {
+	runes := make([]rune, 0, len(cells)+1)
+	hasRTL := false
+	if !rtl {
+		// there's no option forcing a left-to-right paragraph level
+		runes = append(runes, lrm)
+	}
+	for _, c := range cells {
+		r := c.r
+		if c.code {
+			r = 'a'
+		}
+		if p, _ := bidi.LookupRune(r); p.Class() == bidi.R || p.Class() == bidi.AL {
+			hasRTL = true
+		}
+		runes = append(runes, r)
+	}
+	if !hasRTL {
+		return isolates.Replace(line)
+	}
+
+	var p bidi.Paragraph
+	dir := bidi.LeftToRight
+	if rtl {
+		dir = bidi.RightToLeft
+	}
+	if _, err := p.SetString(string(runes), bidi.DefaultDirection(dir)); err != nil {
+		return isolates.Replace(line)
+	}
+	o, err := p.Order()
+	if err != nil {
+		return isolates.Replace(line)
+	}
+
+	offset := len(runes) - len(cells)
+	var runs []bidiRun
+	for i := range o.NumRuns() {
+		run := o.Run(i)
+		start, end := run.Pos()
+		start, end = max(start-offset, 0), end-offset+1
+		if start >= end {
+			continue
+		}
+		r := bidiRun{cells: cells[start:end], rtl: run.Direction() == bidi.RightToLeft}
+		if !r.rtl {
+			r.numbers = leadingNumber(runes[start+offset : end+offset])
+		}
+		runs = append(runs, r)
+	}
+
+	var visual []bidiCell
+	if rtl {
+		// without explicit embeddings, left-to-right runs are the highest
+		// level of right-to-left lines
+		slices.Reverse(runs)
+		for _, r := range runs {
+			visual = append(visual, r.layout()...)
+		}
+		return renderCells(visual)
+	}
+	// right-to-left runs of left-to-right lines are laid out along with the
+	// numbers following them
+	var group []bidiRun
+	flush := func() {
+		slices.Reverse(group)
+		for _, r := range group {
+			visual = append(visual, r.layout()...)
+		}
+		group = nil
+	}
+	for _, r := range runs {
+		switch {
+		case r.rtl:
+			group = append(group, r)
+		case len(group) > 0 && r.numbers > 0:
+			group = append(group, bidiRun{cells: r.cells[:r.numbers]})
+			flush()
+			visual = append(visual, r.cells[r.numbers:]...)
+		default:
+			flush()
+			visual = append(visual, r.cells...)
+		}
+	}
+	flush()
+	return renderCells(visual)
+}
+
+// leadingNumber returns the length of the number text starts with.
+func leadingNumber(runes []rune) int {
+	n := 0
+	for i, r := range runes {
+		p, _ := bidi.LookupRune(r)
+		switch p.Class() {
+		case bidi.EN, bidi.AN:
+			n = i + 1
+		case bidi.ES, bidi.ET, bidi.CS, bidi.NSM:
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// layout returns the cells of a run in visual order.
+func (r bidiRun) layout() []bidiCell {
+	if r.rtl {
+		return reverseCells(r.cells)
+	}
+	return r.cells
+}
+
+// reverseCells reverses a run of right-to-left cells. Combining marks stay
+// after the characters they modify, and brackets are mirrored.
+func reverseCells(cells []bidiCell) []bidiCell {
+	var clusters [][]bidiCell
+	for _, c := range cells {
+		if len(clusters) > 0 && unicode.In(c.r, unicode.Mn, unicode.Me) {
+			clusters[len(clusters)-1] = append(clusters[len(clusters)-1], c)
+			continue
+		}
+		if p, _ := bidi.LookupRune(c.r); p.IsBracket() {
+			c.r, _ = utf8.DecodeRune(bidi.AppendReverse(nil, []byte(string(c.r))))
+		}
+		clusters = append(clusters, []bidiCell{c})
+	}
+	slices.Reverse(clusters)
+	return slices.Concat(clusters...)
+}
+
+// renderCells writes cells along with the escape sequences styling them.
+func renderCells(cells []bidiCell) string {
+	var b strings.Builder
+	var sgr, link string
+	for _, c := range cells {
+		if c.link != link {
+			if link != "" {
+				b.WriteString(hyperlinkReset)
+			}
+			b.WriteString(c.link)
+			link = c.link
+		}
+		if c.sgr != sgr {
+			if sgr != "" {
+				b.WriteString("\x1b[m")
+			}
+			b.WriteString(c.sgr)
+			sgr = c.sgr
+		}
+		b.WriteString(c.marker)
+		b.WriteRune(c.r)
+	}
+	if link != "" {
+		b.WriteString(hyperlinkReset)
+	}
+	if sgr != "" {
+		b.WriteString("\x1b[m")
+	}
+	return b.String()
+}
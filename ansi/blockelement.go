@@ -23,8 +23,8 @@ func (e *BlockElement) Render(w io.Writer, ctx RenderContext) error {
 	bs := ctx.blockStack
 	bs.Push(*e)
 
-	_, _ = renderText(w, bs.Parent().Style.StylePrimitive, e.Style.BlockPrefix)
-	_, _ = renderText(bs.Current().Block, bs.Current().Style.StylePrimitive, e.Style.Prefix)
+	_, _ = ctx.renderText(w, bs.Parent().Style.StylePrimitive, e.Style.BlockPrefix)
+	_, _ = ctx.renderText(bs.Current().Block, bs.Current().Style.StylePrimitive, e.Style.Prefix)
 	return nil
 }
 
@@ -33,11 +33,10 @@ func (e *BlockElement) Finish(w io.Writer, ctx RenderContext) error {
 	bs := ctx.blockStack
 
 	if e.Margin { //nolint: nestif
-		s := lipgloss.Wrap(
-			bs.Current().Block.String(),
-			int(bs.Width(ctx)), //nolint: gosec
-			" ,.;-+|",
-		)
+		width := int(bs.Width(ctx)) //nolint: gosec
+		breakpoints := " ,.;-+|"
+		ctx.sourceMap.wrap(bs.Current().Block, width, breakpoints)
+		s := lipgloss.Wrap(bs.Current().Block.String(), width, breakpoints)
 
 		mw := NewMarginWriter(ctx, w, bs.Current().Style)
 		defer mw.Close() //nolint:errcheck
@@ -57,8 +56,8 @@ func (e *BlockElement) Finish(w io.Writer, ctx RenderContext) error {
 		}
 	}
 
-	_, _ = renderText(w, bs.Current().Style.StylePrimitive, e.Style.Suffix)
-	_, _ = renderText(w, bs.Parent().Style.StylePrimitive, e.Style.BlockSuffix)
+	_, _ = ctx.renderText(w, bs.Current().Style.StylePrimitive, e.Style.Suffix)
+	_, _ = ctx.renderText(w, bs.Parent().Style.StylePrimitive, e.Style.BlockSuffix)
 
 	bs.Current().Block.Reset()
 	bs.Pop()
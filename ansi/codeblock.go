@@ -3,6 +3,7 @@ package ansi
 import (
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 
 	"github.com/alecthomas/chroma/v2"
@@ -126,18 +127,20 @@ func (e *CodeBlockElement) Render(w io.Writer, ctx RenderContext) error {
 	}
 
 	iw := NewIndentWriter(w, int(indentation+margin), func(_ io.Writer) { //nolint:gosec
-		_, _ = renderText(w, bs.Current().Style.StylePrimitive, " ")
+		_, _ = ctx.renderText(w, bs.Current().Style.StylePrimitive, " ")
 	})
 	defer iw.Close() //nolint:errcheck
 
 	if len(theme) > 0 {
-		_, _ = renderText(iw, bs.Current().Style.StylePrimitive, rules.BlockPrefix)
+		_, _ = ctx.renderText(iw, bs.Current().Style.StylePrimitive, rules.BlockPrefix)
 
-		err := quick.Highlight(iw, e.Code, e.Language, formatter, theme)
+		var b strings.Builder
+		err := quick.Highlight(&b, e.Code, e.Language, formatter, theme)
 		if err != nil {
 			return fmt.Errorf("glamour: error highlighting code: %w", err)
 		}
-		_, _ = renderText(iw, bs.Current().Style.StylePrimitive, rules.BlockSuffix)
+		_, _ = io.WriteString(iw, ctx.markSpan(b.String(), bs.With(rules.StylePrimitive)))
+		_, _ = ctx.renderText(iw, bs.Current().Style.StylePrimitive, rules.BlockSuffix)
 		return nil
 	}
 
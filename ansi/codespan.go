@@ -9,7 +9,13 @@ type CodeSpanElement struct {
 }
 
 // Render renders a CodeSpanElement.
-func (e *CodeSpanElement) Render(w io.Writer, _ RenderContext) error {
-	_, _ = renderText(w, e.Style, e.Style.Prefix+e.Text+e.Style.Suffix)
+func (e *CodeSpanElement) Render(w io.Writer, ctx RenderContext) error {
+	// code is laid out left-to-right, even in right-to-left text
+	if ctx.isolateCode() {
+		_, _ = io.WriteString(w, string(lri))
+		defer io.WriteString(w, string(pdi)) //nolint: errcheck
+	}
+	ctx = ctx.withSpan("CodeSpan", "")
+	_, _ = ctx.renderText(w, e.Style, e.Style.Prefix+e.Text+e.Style.Suffix)
 	return nil
 }
@@ -13,17 +13,39 @@ type RenderContext struct {
 
 	blockStack *BlockStack
 	table      *TableElement
+	toc        *tableOfContents
+	sourceMap  *sourceMapper
+	links      *linkFooters
+
+	abbreviations *abbreviations
+	bidi          *bidiState
+
+	// spans records the spans of the rendered text. spanKind and spanLink
+	// are the node kind and link of the text being rendered.
+	spans              *spanRecorder
+	spanKind, spanLink string
 
 	stripper *bluemonday.Policy
 }
 
 // NewRenderContext returns a new RenderContext.
 func NewRenderContext(options Options) RenderContext {
+	stripper := options.HTMLPolicy
+	if stripper == nil {
+		stripper = bluemonday.StrictPolicy()
+	}
 	return RenderContext{
 		options:    options,
 		blockStack: &BlockStack{},
 		table:      &TableElement{},
-		stripper:   bluemonday.StrictPolicy(),
+		toc:        &tableOfContents{},
+		sourceMap:  &sourceMapper{},
+		links:      &linkFooters{},
+		stripper:   stripper,
+
+		abbreviations: &abbreviations{},
+		bidi:          &bidiState{},
+		spans:         &spanRecorder{},
 	}
 }
 
@@ -0,0 +1,176 @@
+package ansi
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"charm.land/glamour/v2/internal/details"
+	"github.com/yuin/goldmark/ast"
+)
+
+// DetailsMode determines how <details> blocks get rendered.
+type DetailsMode int
+
+const (
+	// DetailsNone renders <details> blocks like any other HTML.
+	DetailsNone DetailsMode = iota
+	// DetailsExpanded renders the summary and body of <details> blocks.
+	DetailsExpanded
+	// DetailsCollapsed only renders the summary of <details> blocks, unless
+	// they have the open attribute.
+	DetailsCollapsed
+)
+
+const (
+	defaultDetailsOpen   = "▼ "
+	defaultDetailsClosed = "▶ "
+)
+
+// prepareDetails turns <details> blocks into nodes and removes the bodies of
+// collapsed ones, unless they get rendered folded.
+func (r *ANSIRenderer) prepareDetails(doc ast.Node, source []byte) {
+	if r.context.options.Details == DetailsNone {
+		return
+	}
+	details.Transform(doc, source)
+	if r.folded {
+		return
+	}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if d, ok := n.(*details.Node); ok && entering && !r.context.options.expanded(d) {
+			d.RemoveChildren(d)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// expanded reports whether the body of a <details> block is shown.
+func (o Options) expanded(n *details.Node) bool {
+	return o.Details == DetailsExpanded || n.Open
+}
+
+// A Fold is the body of a <details> block in rendered output, which can be
+// collapsed and expanded.
+type Fold struct {
+	// Line is the disclosure line of the block. It stays visible when the
+	// fold is collapsed.
+	Line int
+	// Start and End are the first and last line of the body.
+	Start, End int
+	// Collapsed is set if the body is hidden.
+	Collapsed bool
+	// Open and Closed are the markers the disclosure line starts with when
+	// the fold is expanded and collapsed.
+	Open, Closed string
+}
+
+// Folds returns the folds of the last rendered document, if recording a
+// source map has been enabled with RecordSourceMap.
+func (r *ANSIRenderer) Folds() []Fold {
+	sm := r.context.sourceMap
+	open, closed := r.context.options.Styles.Details.markers()
+
+	var folds []Fold
+	for i, n := range sm.nodes {
+		d, ok := n.(*details.Node)
+		s := sm.result.Spans[i]
+		if !ok || s.EndLine == s.StartLine {
+			continue
+		}
+		folds = append(folds, Fold{
+			Line:      s.StartLine,
+			Start:     s.StartLine + 1,
+			End:       s.EndLine,
+			Collapsed: !r.context.options.expanded(d),
+			Open:      open,
+			Closed:    closed,
+		})
+	}
+	return folds
+}
+
+// markers returns the markers of expanded and collapsed blocks.
+func (s StyleDetails) markers() (string, string) {
+	open, closed := s.Open, s.Closed
+	if open == "" {
+		open = defaultDetailsOpen
+	}
+	if closed == "" {
+		closed = defaultDetailsClosed
+	}
+	return open, closed
+}
+
+// RenderFolded enables or disables rendering the bodies of collapsed
+// <details> blocks, so they can be unfolded later on.
+func (r *ANSIRenderer) RenderFolded(enabled bool) {
+	r.folded = enabled
+}
+
+// A DetailsElement renders a <details> block as a disclosure line holding
+// its summary, followed by its indented body. Blocks in list items are
+// indented by the width of the item's marker.
+type DetailsElement struct {
+	Summary  string
+	Expanded bool
+	First    bool
+	Last     bool
+	Indent   int
+}
+
+// Render renders a DetailsElement.
+func (e *DetailsElement) Render(w io.Writer, ctx RenderContext) error {
+	bs := ctx.blockStack
+	rules := ctx.options.Styles.Details
+	style := cascadeStyle(bs.Current().Style, rules.StyleBlock, false)
+	indent := uint(e.Indent) //nolint: gosec
+	if style.Indent != nil {
+		indent += *style.Indent
+	}
+	style.Indent = &indent
+
+	b := bs.Current().Block
+	if !e.First {
+		_, _ = io.WriteString(b, "\n")
+	}
+	marker, closed := rules.markers()
+	if !e.Expanded {
+		marker = closed
+	}
+	// the first block of a list item follows its marker
+	if !e.First {
+		_, _ = io.WriteString(b, strings.Repeat(" ", e.Indent))
+	}
+	_, _ = ctx.renderText(b, cascadeStylePrimitives(style.StylePrimitive, rules.Summary), marker+e.Summary)
+	_, _ = io.WriteString(b, "\n")
+
+	be := &BlockElement{
+		Block:  &bytes.Buffer{},
+		Style:  style,
+		Margin: true,
+	}
+	return be.Render(w, ctx)
+}
+
+// Finish finishes rendering a DetailsElement.
+func (e *DetailsElement) Finish(w io.Writer, ctx RenderContext) error {
+	// HTML blocks don't end with a newline, while the last block of a list
+	// item leaves it to the item
+	bs := ctx.blockStack
+	b := bs.Current().Block
+	if e.Last && e.Indent > 0 {
+		b.Truncate(len(bytes.TrimRight(b.Bytes(), "\n")))
+		if p := bs.Parent().Block; b.Len() == 0 {
+			p.Truncate(len(bytes.TrimSuffix(p.Bytes(), []byte("\n"))))
+		}
+	} else if b.Len() > 0 && !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		_, _ = io.WriteString(b, "\n")
+	}
+	be := &BlockElement{
+		Style:  ctx.options.Styles.Details.StyleBlock,
+		Margin: true,
+	}
+	return be.Finish(w, ctx)
+}
@@ -0,0 +1,56 @@
+package ansi
+
+import (
+	"io"
+
+	"charm.land/glamour/v2/internal/mermaid"
+	"charm.land/lipgloss/v2"
+)
+
+// A DiagramElement is used to render Mermaid diagrams.
+type DiagramElement struct {
+	Code string
+}
+
+// Render renders a DiagramElement. Flowcharts and sequence diagrams are drawn
+// as text; other diagrams, and ones that don't fit, are rendered as code.
+func (e *DiagramElement) Render(w io.Writer, ctx RenderContext) error {
+	bs := ctx.blockStack
+	rules := ctx.options.Styles.Diagram
+
+	var indentation, margin uint
+	if rules.Indent != nil {
+		indentation = *rules.Indent
+	}
+	if rules.Margin != nil {
+		margin = *rules.Margin
+	}
+	border, ok := tableBorders[rules.Border]
+	if !ok {
+		border = lipgloss.NormalBorder()
+	}
+
+	width := int(bs.Width(ctx)) - int(indentation+margin) //nolint: gosec
+	lines, err := mermaid.Render(e.Code, width, border)
+	if err != nil {
+		el := &CodeBlockElement{
+			Code:     e.Code,
+			Language: "mermaid",
+		}
+		return el.Render(w, ctx)
+	}
+
+	iw := NewIndentWriter(w, int(indentation+margin), func(_ io.Writer) { //nolint:gosec
+		_, _ = ctx.renderText(w, bs.Current().Style.StylePrimitive, " ")
+	})
+	defer iw.Close() //nolint:errcheck
+
+	style := cascadeStylePrimitives(bs.Current().Style.StylePrimitive, rules.StylePrimitive)
+	_, _ = ctx.renderText(iw, bs.Current().Style.StylePrimitive, rules.BlockPrefix)
+	for _, line := range lines {
+		_, _ = ctx.renderText(iw, style, line)
+		_, _ = io.WriteString(iw, "\n")
+	}
+	_, _ = ctx.renderText(iw, bs.Current().Style.StylePrimitive, rules.BlockSuffix)
+	return nil
+}
@@ -7,7 +7,13 @@ import (
 	"io"
 	"strings"
 
-	"charm.land/glamour/v2/internal/autolink"
+	"charm.land/glamour/v2/internal/abbr"
+	"charm.land/glamour/v2/internal/details"
+	"charm.land/glamour/v2/internal/frontmatter"
+	"charm.land/glamour/v2/internal/inlinehtml"
+	"charm.land/glamour/v2/internal/latex"
+	"charm.land/glamour/v2/internal/mark"
+	"charm.land/glamour/v2/internal/reference"
 	east "github.com/yuin/goldmark-emoji/ast"
 	"github.com/yuin/goldmark/ast"
 	astext "github.com/yuin/goldmark/extension/ast"
@@ -60,6 +66,7 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 		he := &HeadingElement{
 			Level: n.Level,
 			First: node.PreviousSibling() == nil,
+			entry: ctx.toc.entry(node),
 		}
 		return Element{
 			Exiting:  "",
@@ -67,12 +74,43 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 			Finisher: he,
 		}
 
+	// Front matter
+	case frontmatter.KindFrontMatter:
+		e := NewFrontMatterElement(ctx, node.(*frontmatter.Node), source)
+		return Element{
+			Renderer: e,
+			Finisher: e,
+		}
+
+	// Details
+	case details.KindDetails:
+		n := node.(*details.Node)
+		e := &DetailsElement{
+			Summary:  n.Summary,
+			Expanded: ctx.options.expanded(n),
+			First:    node.PreviousSibling() == nil,
+			Last:     node.NextSibling() == nil,
+		}
+		e.Indent, _ = tr.hangingIndent(node)
+		return Element{
+			Renderer: e,
+			Finisher: e,
+		}
+
 	// Paragraph
 	case ast.KindParagraph:
 		if node.Parent() != nil {
 			kind := node.Parent().Kind()
 			if kind == ast.KindListItem {
-				return Element{}
+				hang, _ := tr.hangingIndent(node)
+				e := &HangingElement{
+					Indent: hang,
+					First:  node.PreviousSibling() == nil,
+				}
+				return Element{
+					Renderer: e,
+					Finisher: e,
+				}
 			}
 		}
 		return Element{
@@ -125,46 +163,15 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 		}
 
 	case ast.KindListItem:
-		var l uint
-		var e uint
-		l = 1
-		n := node
-		for n.PreviousSibling() != nil && (n.PreviousSibling().Kind() == ast.KindListItem) {
-			l++
-			n = n.PreviousSibling()
-		}
-		if node.Parent().(*ast.List).IsOrdered() {
-			e = l
-			if node.Parent().(*ast.List).Start != 1 {
-				e += uint(node.Parent().(*ast.List).Start) - 1 //nolint: gosec
-			}
-		}
-
 		post := "\n"
 		if (node.LastChild() != nil && node.LastChild().Kind() == ast.KindList) ||
 			node.NextSibling() == nil {
 			post = ""
 		}
 
-		if node.FirstChild() != nil &&
-			node.FirstChild().FirstChild() != nil &&
-			node.FirstChild().FirstChild().Kind() == astext.KindTaskCheckBox {
-			nc := node.FirstChild().FirstChild().(*astext.TaskCheckBox)
-
-			return Element{
-				Exiting: post,
-				Renderer: &TaskElement{
-					Checked: nc.IsChecked,
-				},
-			}
-		}
-
 		return Element{
-			Exiting: post,
-			Renderer: &ItemElement{
-				IsOrdered:   node.Parent().(*ast.List).IsOrdered(),
-				Enumeration: e,
-			},
+			Exiting:  post,
+			Renderer: listItemRenderer(node),
 		}
 
 	// Text Elements
@@ -175,9 +182,21 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 		if n.HardLineBreak() || (n.SoftLineBreak()) {
 			s += "\n"
 		}
+		s = html.UnescapeString(s)
+		if ctx.options.Emoji.WidthSafe {
+			s = dropPresentationSelectors(s)
+		}
 		return Element{
 			Renderer: &BaseElement{
-				Token: html.UnescapeString(s),
+				Token: s,
+				Style: ctx.options.Styles.Text,
+			},
+		}
+	case ast.KindString:
+		n := node.(*ast.String)
+		return Element{
+			Renderer: &BaseElement{
+				Token: string(n.Value),
 				Style: ctx.options.Styles.Text,
 			},
 		}
@@ -221,27 +240,27 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 	// Links
 	case ast.KindLink:
 		n := node.(*ast.Link)
-		isFooterLinks := !ctx.options.InlineTableLinks && isInsideTable(node)
-
-		var children []ElementRenderer
 		content, err := nodeContent(node, source)
+		tl := tableLink{
+			content:  string(content),
+			href:     string(n.Destination),
+			title:    string(n.Title),
+			linkType: linkTypeRegular,
+		}
+		links, isFooterLinks := footerLinks(ctx, node, tl)
 
-		if isFooterLinks && err == nil {
-			text := string(content)
-			tl := tableLink{
-				content:  text,
-				href:     string(n.Destination),
-				title:    string(n.Title),
-				linkType: linkTypeRegular,
-			}
-			text = linkWithSuffix(tl, ctx.table.tableLinks)
-			children = []ElementRenderer{&BaseElement{Token: text}}
+		var children []ElementRenderer
+		if isFooterLinks && err == nil && isInsideTable(node) {
+			children = []ElementRenderer{&BaseElement{Token: linkWithSuffix(tl, links)}}
 		} else {
 			nn := n.FirstChild()
 			for nn != nil {
 				children = append(children, tr.NewElement(nn, source).Renderer)
 				nn = nn.NextSibling()
 			}
+			if isFooterLinks {
+				children = append(children, &BaseElement{Token: linkSuffix(tl, links)})
+			}
 		}
 
 		return Element{
@@ -255,7 +274,6 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 	case ast.KindAutoLink:
 		n := node.(*ast.AutoLink)
 		u := string(n.URL(source))
-		isFooterLinks := !ctx.options.InlineTableLinks && isInsideTable(node)
 
 		var children []ElementRenderer
 		nn := n.FirstChild()
@@ -272,25 +290,37 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 			u = "mailto:" + u
 		}
 
+		tl := tableLink{
+			content:  linkDomain(u),
+			href:     u,
+			linkType: linkTypeAuto,
+		}
+		shortened, isShortened := shortenURL(ctx, u)
+		if isShortened {
+			tl.content = shortened
+		}
+		links, isFooterLinks := footerLinks(ctx, node, tl)
+		// the URL is only kept as a hyperlink in prose
+		isShortened = isShortened && !ctx.options.FullAutolinks
+
 		var renderer ElementRenderer
-		if isFooterLinks {
-			domain := linkDomain(u)
-			tl := tableLink{
-				content:  domain,
-				href:     u,
-				linkType: linkTypeAuto,
-			}
-			if shortned, ok := autolink.Detect(u); ok {
-				tl.content = shortned
-			}
-			text := linkWithSuffix(tl, ctx.table.tableLinks)
+		switch {
+		case isFooterLinks:
+			text := linkWithSuffix(tl, links)
 
 			renderer = &LinkElement{
 				Children: []ElementRenderer{&BaseElement{Token: text}},
 				URL:      u,
 				SkipHref: true,
 			}
-		} else {
+		case isShortened:
+			// show the short form, linking to the URL
+			renderer = &LinkElement{
+				Children: []ElementRenderer{&BaseElement{Token: shortened}},
+				URL:      u,
+				SkipHref: true,
+			}
+		default:
 			isEmail := n.AutoLinkType == ast.AutoLinkEmail
 			renderer = &LinkElement{
 				Children: children,
@@ -301,23 +331,35 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 		}
 		return Element{Renderer: renderer}
 
+	case reference.KindReference:
+		n := node.(*reference.Node)
+		style := cascadeStylePrimitives(ctx.options.Styles.LinkText, ctx.options.Styles.Reference)
+		return Element{
+			Renderer: &LinkElement{
+				URL:       n.URL,
+				Children:  []ElementRenderer{&BaseElement{Token: n.Label}},
+				SkipHref:  true,
+				TextStyle: &style,
+			},
+		}
+
 	// Images
 	case ast.KindImage:
 		n := node.(*ast.Image)
 		text := string(n.Text(source)) //nolint: staticcheck
-		isFooterLinks := !ctx.options.InlineTableLinks && isInsideTable(node)
+		tl := tableLink{
+			title:    string(n.Title),
+			content:  text,
+			href:     string(n.Destination),
+			linkType: linkTypeImage,
+		}
+		if tl.content == "" {
+			tl.content = linkDomain(tl.href)
+		}
+		links, isFooterLinks := footerLinks(ctx, node, tl)
 
 		if isFooterLinks {
-			if text == "" {
-				text = linkDomain(string(n.Destination))
-			}
-			tl := tableLink{
-				title:    string(n.Title),
-				content:  text,
-				href:     string(n.Destination),
-				linkType: linkTypeImage,
-			}
-			text = linkWithSuffix(tl, ctx.table.tableImages)
+			text = linkWithSuffix(tl, links)
 		}
 
 		return Element{
@@ -338,11 +380,20 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 			line := n.Lines().At(i)
 			s += string(line.Value(source))
 		}
+		language := string(n.Language(source))
+		if ctx.options.Diagrams && language == "mermaid" {
+			return Element{
+				Entering: "\n",
+				Renderer: &DiagramElement{
+					Code: s,
+				},
+			}
+		}
 		return Element{
 			Entering: "\n",
 			Renderer: &CodeBlockElement{
 				Code:     s,
-				Language: string(n.Language(source)),
+				Language: language,
 			},
 		}
 
@@ -371,12 +422,31 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 			},
 		}
 
+	// Math
+	case latex.KindInlineMath:
+		n := node.(*latex.InlineMath)
+		return Element{
+			Renderer: &MathInlineElement{
+				TeX:   n.TeX,
+				Style: cascadeStyle(ctx.blockStack.Current().Style, ctx.options.Styles.Math, false).StylePrimitive,
+			},
+		}
+	case latex.KindMathBlock:
+		n := node.(*latex.MathBlock)
+		return Element{
+			Entering: "\n",
+			Renderer: &MathBlockElement{
+				TeX: n.TeX(source),
+			},
+		}
+
 	// Tables
 	case astext.KindTable:
 		table := node.(*astext.Table)
 		te := &TableElement{
-			table:  table,
-			source: source,
+			table:       table,
+			source:      source,
+			columnModes: parseColumnModes(node, source),
 		}
 		return Element{
 			Entering: "\n",
@@ -386,17 +456,19 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 		}
 
 	case astext.KindTableCell:
-		n := node.(*astext.TableCell)
-		var children []ElementRenderer
-		nn := n.FirstChild()
-		for nn != nil {
-			children = append(children, tr.NewElement(nn, source).Renderer)
-			nn = nn.NextSibling()
+		children := tr.tableCellChildren(node, source)
+
+		var row int
+		for nn := node.Parent().PreviousSibling(); nn != nil; nn = nn.PreviousSibling() {
+			if nn.Kind() == astext.KindTableRow {
+				row++
+			}
 		}
 
 		r := &TableCellElement{
 			Children: children,
 			Head:     node.Parent().Kind() == astext.KindTableHeader,
+			Row:      row,
 		}
 		return Element{
 			Renderer: r,
@@ -422,6 +494,17 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 		}
 	case ast.KindRawHTML:
 		n := node.(*ast.RawHTML)
+		if t, ok := inlinehtml.ParseTag(inlinehtml.Text(n, source)); ok && t.Name == "br" && !t.Closing {
+			token := "\n"
+			if n.Parent().Kind() == ast.KindParagraph {
+				token = hardBreak
+			}
+			return Element{
+				Renderer: &BaseElement{
+					Token: token,
+				},
+			}
+		}
 		return Element{
 			Renderer: &BaseElement{
 				Token: ctx.SanitizeHTML(string(n.Text(source)), true), //nolint: staticcheck
@@ -429,6 +512,20 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 			},
 		}
 
+	case mark.KindMark:
+		n := node.(*mark.Node)
+		return Element{
+			Renderer: tr.newMarkElement(n.Mark, n, source),
+		}
+	case inlinehtml.KindSpan:
+		return Element{
+			Renderer: tr.newHTMLSpanElement(node.(*inlinehtml.Span), source),
+		}
+	case abbr.KindAbbreviation:
+		return Element{
+			Renderer: tr.newAbbreviationElement(node.(*abbr.Abbreviation), source),
+		}
+
 	// Definition Lists
 	case astext.KindDefinitionList:
 		e := &BlockElement{
@@ -463,13 +560,23 @@ func (tr *ANSIRenderer) NewElement(node ast.Node, source []byte) Element {
 		// handled by KindListItem
 		return Element{}
 	case ast.KindTextBlock:
+		if hang, ok := tr.hangingIndent(node); ok {
+			e := &HangingElement{
+				Indent: hang,
+				First:  node.PreviousSibling() == nil,
+			}
+			return Element{
+				Renderer: e,
+				Finisher: e,
+			}
+		}
 		return Element{}
 
 	case east.KindEmoji:
 		n := node.(*east.Emoji)
 		return Element{
 			Renderer: &BaseElement{
-				Token: string(n.Value.Unicode),
+				Token: ctx.options.Emoji.emojiText(n),
 			},
 		}
 
@@ -0,0 +1,112 @@
+package ansi
+
+import (
+	"strings"
+
+	xansi "github.com/charmbracelet/x/ansi"
+	east "github.com/yuin/goldmark-emoji/ast"
+)
+
+// EmojiFallback determines what emoji shortcodes get rendered as.
+type EmojiFallback int
+
+const (
+	// EmojiUnicode renders emoji as Unicode characters.
+	EmojiUnicode EmojiFallback = iota
+	// EmojiShortcode renders emoji as their :shortcode:, for terminals
+	// without an emoji font.
+	EmojiShortcode
+	// EmojiASCII renders emoji as ASCII emoticons like :-) where there's
+	// one, and as their :shortcode: otherwise.
+	EmojiASCII
+)
+
+// EmojiOptions configures how emoji shortcodes are parsed and rendered.
+type EmojiOptions struct {
+	// Custom maps shortcodes, without colons, to the text they're rendered
+	// as, regardless of the fallback. Custom shortcodes take precedence
+	// over the GitHub ones.
+	Custom map[string]string
+	// Allow limits the GitHub shortcodes that get parsed to the ones listed.
+	// All of them are parsed if it's empty.
+	Allow []string
+	// Fallback determines what emoji get rendered as.
+	Fallback EmojiFallback
+	// WidthSafe drops emoji presentation selectors following characters
+	// that are narrow on their own, like in ☀️, from shortcodes and text
+	// alike. Terminals disagree on the width of such emoji, which throws off
+	// word wrapping and alignment.
+	WidthSafe bool
+}
+
+// asciiEmoticons holds the ASCII emoticons of emoji shortcodes.
+var asciiEmoticons = map[string]string{
+	"smile":                  ":)",
+	"slightly_smiling_face":  ":)",
+	"smiley":                 ":-)",
+	"grinning":               ":D",
+	"laughing":               "XD",
+	"satisfied":              "XD",
+	"wink":                   ";)",
+	"disappointed":           ":(",
+	"slightly_frowning_face": ":(",
+	"cry":                    ":'(",
+	"angry":                  ">:(",
+	"stuck_out_tongue":       ":P",
+	"open_mouth":             ":O",
+	"neutral_face":           ":|",
+	"confused":               ":/",
+	"sunglasses":             "B-)",
+	"kissing":                ":*",
+	"innocent":               "O:)",
+	"heart":                  "<3",
+	"broken_heart":           "</3",
+	"+1":                     "(y)",
+	"thumbsup":               "(y)",
+	"-1":                     "(n)",
+	"thumbsdown":             "(n)",
+	"white_check_mark":       "[x]",
+	"heavy_check_mark":       "[x]",
+	"warning":                "/!\\",
+	"arrow_right":            "->",
+	"arrow_left":             "<-",
+}
+
+// emojiText returns the text an emoji gets rendered as.
+func (o EmojiOptions) emojiText(n *east.Emoji) string {
+	name := string(n.ShortName)
+	if s, ok := o.Custom[name]; ok {
+		return s
+	}
+
+	switch o.Fallback {
+	case EmojiShortcode:
+		return ":" + name + ":"
+	case EmojiASCII:
+		if s, ok := asciiEmoticons[name]; ok {
+			return s
+		}
+		return ":" + name + ":"
+	}
+
+	s := string(n.Value.Unicode)
+	if o.WidthSafe {
+		s = dropPresentationSelectors(s)
+	}
+	return s
+}
+
+// dropPresentationSelectors removes the emoji presentation selectors
+// following characters that are narrow without them.
+func dropPresentationSelectors(s string) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range s {
+		if r == '\uFE0F' && xansi.StringWidthWc(string(prev)) < 2 {
+			continue
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String()
+}
@@ -31,6 +31,11 @@ func (e *EmphasisElement) StyleOverrideRender(w io.Writer, ctx RenderContext, st
 }
 
 func (e *EmphasisElement) doRender(w io.Writer, ctx RenderContext, style StylePrimitive) error {
+	if e.Level > 1 {
+		ctx = ctx.withSpan("Strong", "")
+	} else {
+		ctx = ctx.withSpan("Emphasis", "")
+	}
 	for _, child := range e.Children {
 		if r, ok := child.(StyleOverriderElementRenderer); ok {
 			if err := r.StyleOverrideRender(w, ctx, style); err != nil {
@@ -0,0 +1,182 @@
+package ansi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"charm.land/glamour/v2/internal/frontmatter"
+	"charm.land/lipgloss/v2"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark/ast"
+)
+
+// FrontMatterMode determines how the front matter of a document gets
+// rendered.
+type FrontMatterMode int
+
+const (
+	// FrontMatterStrip leaves the front matter out.
+	FrontMatterStrip FrontMatterMode = iota
+	// FrontMatterRender renders the front matter as a box of keys and values.
+	FrontMatterRender
+	// FrontMatterTitle renders the title field of the front matter as a
+	// top-level heading.
+	FrontMatterTitle
+)
+
+const defaultFrontMatterSeparator = ": "
+
+// prepareFrontMatter records the front matter of the document and, unless
+// it gets rendered as is, replaces it by a heading or removes it.
+func (r *ANSIRenderer) prepareFrontMatter(doc ast.Node) {
+	r.frontMatter = nil
+	fm, ok := doc.FirstChild().(*frontmatter.Node)
+	if !ok {
+		return
+	}
+	r.frontMatter = fm.Data
+
+	switch r.context.options.FrontMatter {
+	case FrontMatterRender:
+		return
+	case FrontMatterTitle:
+		if title := formatFrontMatterValue(fm.Data["title"]); title != "" {
+			h := ast.NewHeading(h1)
+			h.AppendChild(h, ast.NewString([]byte(title)))
+			doc.ReplaceChild(doc, fm, h)
+			return
+		}
+	case FrontMatterStrip:
+	}
+	doc.RemoveChild(doc, fm)
+}
+
+// FrontMatter returns the front matter of the last rendered document, or nil
+// if it had none.
+func (r *ANSIRenderer) FrontMatter() map[string]any {
+	return r.frontMatter
+}
+
+// A FrontMatterElement is used to render front matter as a box of keys and
+// values.
+type FrontMatterElement struct {
+	BlockElement
+	Node   *frontmatter.Node
+	Source []byte
+}
+
+// NewFrontMatterElement returns a FrontMatterElement for the given node.
+func NewFrontMatterElement(ctx RenderContext, node *frontmatter.Node, source []byte) *FrontMatterElement {
+	return &FrontMatterElement{
+		BlockElement: BlockElement{
+			Block:   &bytes.Buffer{},
+			Style:   cascadeStyle(ctx.blockStack.Current().Style, ctx.options.Styles.FrontMatter.StyleBlock, false),
+			Margin:  true,
+			Newline: true,
+		},
+		Node:   node,
+		Source: source,
+	}
+}
+
+// Render renders a FrontMatterElement.
+func (e *FrontMatterElement) Render(w io.Writer, ctx RenderContext) error {
+	bs := ctx.blockStack
+	rules := ctx.options.Styles.FrontMatter
+
+	if err := e.BlockElement.Render(w, ctx); err != nil {
+		return err
+	}
+
+	style := bs.Current().Style.StylePrimitive
+	keyStyle := cascadeStylePrimitives(style, rules.Key)
+	valueStyle := cascadeStylePrimitives(style, rules.Value)
+
+	sep := defaultFrontMatterSeparator
+	if rules.Separator != nil {
+		sep = *rules.Separator
+	}
+
+	var keyWidth int
+	for _, k := range e.Node.Keys {
+		keyWidth = max(keyWidth, xansi.StringWidth(k))
+	}
+
+	border, boxed := tableBorders[rules.Border]
+	box := lipgloss.NewStyle().Border(border).Padding(0, 1)
+	if rules.BorderColor != nil {
+		box = box.BorderForeground(lipgloss.Color(*rules.BorderColor))
+	}
+	width := int(bs.Width(ctx)) //nolint: gosec
+	if boxed {
+		// leave room for the border and padding
+		width -= box.GetHorizontalFrameSize()
+	}
+
+	// wrapped values line up with the value column
+	indent := keyWidth + xansi.StringWidth(sep)
+	valueWidth := max(width-indent, 1)
+
+	var b bytes.Buffer
+	for i, k := range e.Node.Keys {
+		if i > 0 {
+			_, _ = io.WriteString(&b, "\n")
+		}
+		_, _ = ctx.renderText(&b, keyStyle, k+sep)
+		_, _ = ctx.renderText(&b, style, strings.Repeat(" ", keyWidth-xansi.StringWidth(k)))
+		value := lipgloss.Wrap(formatFrontMatterValue(e.Node.Data[k]), valueWidth, " ,")
+		for j, line := range strings.Split(value, "\n") {
+			if j > 0 {
+				_, _ = io.WriteString(&b, "\n")
+				_, _ = ctx.renderText(&b, style, strings.Repeat(" ", indent))
+			}
+			_, _ = ctx.renderText(&b, valueStyle, line)
+		}
+	}
+
+	if !boxed {
+		_, _ = b.WriteTo(bs.Current().Block)
+		return nil
+	}
+	_, _ = io.WriteString(bs.Current().Block, box.Render(lipgloss.Wrap(b.String(), width, " ,")))
+	return nil
+}
+
+// formatFrontMatterValue formats a front matter value for display. Lists are
+// joined by commas and dates without a time of day are printed as such.
+func formatFrontMatterValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format(time.RFC3339)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatFrontMatterValue(item)
+		}
+		return strings.Join(items, ", ")
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			items[i] = k + defaultFrontMatterSeparator + formatFrontMatterValue(v[k])
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,106 @@
+package ansi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark/ast"
+	astext "github.com/yuin/goldmark/extension/ast"
+)
+
+// A HangingElement is used to render the text of list items and definitions.
+// Wrapped lines are indented so they line up with the text after the item's
+// marker instead of the marker itself.
+type HangingElement struct {
+	Indent int
+	First  bool
+}
+
+// Render renders a HangingElement.
+func (e *HangingElement) Render(w io.Writer, ctx RenderContext) error {
+	bs := ctx.blockStack
+
+	if !e.First {
+		_, _ = io.WriteString(w, "\n")
+	}
+
+	indent := uint(e.Indent) //nolint: gosec
+	if indent >= bs.Width(ctx) {
+		indent = 0
+	}
+	style := bs.Current().Style
+	style.Indent = &indent
+	style.Margin = nil
+
+	bs.Push(BlockElement{
+		Block: &bytes.Buffer{},
+		Style: style,
+	})
+	ctx.bidi.paragraph = true
+	return nil
+}
+
+// Finish finishes rendering a HangingElement.
+func (e *HangingElement) Finish(w io.Writer, ctx RenderContext) error {
+	bs := ctx.blockStack
+
+	s := lipgloss.Wrap(
+		bs.Current().Block.String(),
+		int(bs.Width(ctx)), //nolint: gosec
+		" ,.;-+|",
+	)
+	s = ctx.bidiParagraph(s, ctx.bidiWidth(), bs.Current().Style.StylePrimitive)
+
+	indent := strings.Repeat(" ", int(*bs.Current().Style.Indent)) //nolint: gosec
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return fmt.Errorf("glamour: error writing to writer: %w", err)
+			}
+		}
+		// the first line follows the item's marker
+		if (i > 0 || !e.First) && len(line) > 0 {
+			_, _ = ctx.renderText(w, bs.Parent().Style.StylePrimitive, indent)
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return fmt.Errorf("glamour: error writing to writer: %w", err)
+		}
+	}
+
+	bs.Current().Block.Reset()
+	bs.Pop()
+	ctx.bidi.paragraph = false
+	return nil
+}
+
+// hangingIndent returns the width of the marker in front of a list item's or
+// definition's text, and whether node is such a text at all.
+func (tr *ANSIRenderer) hangingIndent(node ast.Node) (int, bool) {
+	ctx := tr.context
+
+	var el ElementRenderer
+	switch node.Parent().Kind() {
+	case ast.KindListItem:
+		el = listItemRenderer(node.Parent())
+	case astext.KindDefinitionDescription:
+		el = &BaseElement{
+			Style: ctx.options.Styles.DefinitionDescription,
+		}
+	default:
+		return 0, false
+	}
+
+	var buf bytes.Buffer
+	if err := el.Render(&buf, ctx); err != nil {
+		return 0, true
+	}
+	marker := buf.String()
+	if i := strings.LastIndex(marker, "\n"); i >= 0 {
+		marker = marker[i+1:]
+	}
+	return ansi.StringWidth(marker), true
+}
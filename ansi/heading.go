@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 
 	"charm.land/lipgloss/v2"
 )
@@ -12,6 +13,8 @@ import (
 type HeadingElement struct {
 	Level int
 	First bool
+
+	entry *TOCEntry
 }
 
 const (
@@ -44,7 +47,7 @@ func (e *HeadingElement) Render(w io.Writer, ctx RenderContext) error {
 	}
 
 	if !e.First {
-		_, _ = renderText(w, bs.Current().Style.StylePrimitive, "\n")
+		_, _ = ctx.renderText(w, bs.Current().Style.StylePrimitive, "\n")
 	}
 
 	be := BlockElement{
@@ -52,9 +55,13 @@ func (e *HeadingElement) Render(w io.Writer, ctx RenderContext) error {
 		Style: cascadeStyle(bs.Current().Style, rules, false),
 	}
 	bs.Push(be)
+	ctx.bidi.paragraph = true
 
-	_, _ = renderText(w, bs.Parent().Style.StylePrimitive, rules.BlockPrefix)
-	_, _ = renderText(bs.Current().Block, bs.Current().Style.StylePrimitive, rules.Prefix)
+	_, _ = ctx.renderText(w, bs.Parent().Style.StylePrimitive, rules.BlockPrefix)
+	_, _ = ctx.renderText(bs.Current().Block, bs.Current().Style.StylePrimitive, rules.Prefix)
+	if e.entry != nil && e.entry.Number != "" {
+		_, _ = ctx.renderText(bs.Current().Block, bs.Current().Style.StylePrimitive, e.entry.Number+" ")
+	}
 	return nil
 }
 
@@ -62,19 +69,28 @@ func (e *HeadingElement) Render(w io.Writer, ctx RenderContext) error {
 func (e *HeadingElement) Finish(w io.Writer, ctx RenderContext) error {
 	bs := ctx.blockStack
 	rules := bs.Current().Style
+
+	if e.entry != nil {
+		// nested headings report the line of the outermost block they're in
+		e.entry.Line = bytes.Count((*bs)[0].Block.Bytes(), []byte("\n")) +
+			strings.Count(ctx.options.Styles.Document.BlockPrefix, "\n")
+	}
+
 	mw := NewMarginWriter(ctx, w, rules)
 	defer mw.Close() //nolint:errcheck
 
 	flow := lipgloss.Wrap(bs.Current().Block.String(), int(bs.Width(ctx)), "") //nolint: gosec
+	flow = ctx.bidiParagraph(flow, ctx.bidiWidth(), bs.Parent().Style.StylePrimitive)
 	_, err := io.WriteString(mw, flow)
 	if err != nil {
 		return fmt.Errorf("glamour: error writing to writer: %w", err)
 	}
 
-	_, _ = renderText(w, bs.Current().Style.StylePrimitive, rules.Suffix)
-	_, _ = renderText(w, bs.Parent().Style.StylePrimitive, rules.BlockSuffix)
+	_, _ = ctx.renderText(w, bs.Current().Style.StylePrimitive, rules.Suffix)
+	_, _ = ctx.renderText(w, bs.Parent().Style.StylePrimitive, rules.BlockSuffix)
 
 	bs.Current().Block.Reset()
 	bs.Pop()
+	ctx.bidi.paragraph = false
 	return nil
 }
@@ -0,0 +1,137 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"charm.land/glamour/v2/internal/inlinehtml"
+	"charm.land/glamour/v2/internal/mark"
+	"github.com/yuin/goldmark/ast"
+	astext "github.com/yuin/goldmark/extension/ast"
+)
+
+// hardBreak marks a line break in a paragraph that's kept when the paragraph
+// is wrapped.
+const hardBreak = "\u2028"
+
+// A StyledElement is used to render inline content in a style, like
+// emphasis.
+type StyledElement struct {
+	Children []ElementRenderer
+	Style    StylePrimitive
+}
+
+// Render renders a StyledElement.
+func (e *StyledElement) Render(w io.Writer, ctx RenderContext) error {
+	return e.StyleOverrideRender(w, ctx, StylePrimitive{})
+}
+
+// StyleOverrideRender renders a StyledElement with a given style.
+func (e *StyledElement) StyleOverrideRender(w io.Writer, ctx RenderContext, style StylePrimitive) error {
+	style = cascadeStylePrimitives(style, e.Style)
+	_, _ = ctx.renderText(w, style, e.Style.Prefix)
+
+	// the prefix and suffix are written once, not around every child
+	inner := style
+	inner.Prefix, inner.Suffix = "", ""
+	for _, child := range e.Children {
+		if r, ok := child.(StyleOverriderElementRenderer); ok {
+			if err := r.StyleOverrideRender(w, ctx, inner); err != nil {
+				return fmt.Errorf("glamour: error rendering with style: %w", err)
+			}
+		} else {
+			if err := child.Render(w, ctx); err != nil {
+				return fmt.Errorf("glamour: error rendering: %w", err)
+			}
+		}
+	}
+	_, _ = ctx.renderText(w, style, e.Style.Suffix)
+	return nil
+}
+
+// prepareHTML turns the inline HTML tags the renderer understands into
+// nodes. Bold, italic and struck-out text, code, links and images become
+// their markdown counterparts, and <mark>, <ins>, <sup> and <sub> become
+// marked text; other tags are left to be sanitized.
+func (r *ANSIRenderer) prepareHTML(doc ast.Node, source []byte) {
+	inlinehtml.Transform(doc, source, func(t inlinehtml.Tag) ast.Node {
+		if t.Void && t.Name != "img" {
+			return nil
+		}
+		switch t.Name {
+		case "b", "strong":
+			return ast.NewEmphasis(2)
+		case "i", "em":
+			return ast.NewEmphasis(1)
+		case "s", "del", "strike":
+			return astext.NewStrikethrough()
+		case "code":
+			return ast.NewCodeSpan()
+		case "a":
+			if t.Attrs["href"] == "" {
+				return nil
+			}
+			n := ast.NewLink()
+			n.Destination = []byte(t.Attrs["href"])
+			n.Title = []byte(t.Attrs["title"])
+			return n
+		case "img":
+			if t.Attrs["src"] == "" {
+				return nil
+			}
+			l := ast.NewLink()
+			l.Destination = []byte(t.Attrs["src"])
+			l.Title = []byte(t.Attrs["title"])
+			n := ast.NewImage(l)
+			n.AppendChild(n, ast.NewString([]byte(t.Attrs["alt"])))
+			return n
+		case "mark":
+			return &mark.Node{Mark: mark.Highlight}
+		case "ins":
+			return &mark.Node{Mark: mark.Inserted}
+		case "sup":
+			return &mark.Node{Mark: mark.Superscript}
+		case "sub":
+			return &mark.Node{Mark: mark.Subscript}
+		case "kbd", "u":
+			return &inlinehtml.Span{Tag: t.Name}
+		}
+		return nil
+	})
+}
+
+// newHTMLSpanElement returns the element rendering an HTML span.
+func (tr *ANSIRenderer) newHTMLSpanElement(n *inlinehtml.Span, source []byte) ElementRenderer {
+	ctx := tr.context
+
+	var children []ElementRenderer
+	for nn := n.FirstChild(); nn != nil; nn = nn.NextSibling() {
+		children = append(children, tr.NewElement(nn, source).Renderer)
+	}
+	switch n.Tag {
+	case "kbd":
+		return &StyledElement{Children: children, Style: ctx.options.Styles.Kbd}
+	case "u":
+		return &StyledElement{Children: children, Style: ctx.options.Styles.Underline}
+	}
+	return &StyledElement{Children: children}
+}
+
+// plainText returns the text of a node and its descendants.
+func plainText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			b.Write(n.Segment.Value(source))
+		case *ast.String:
+			b.Write(n.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
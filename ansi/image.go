@@ -16,13 +16,24 @@ type ImageElement struct {
 
 // Render renders an ImageElement.
 func (e *ImageElement) Render(w io.Writer, ctx RenderContext) error {
+	href, ok := ctx.resolveLink(e.URL)
+	ctx = ctx.withSpan("Image", href)
+
 	// Make OSC 8 hyperlink token.
-	hyperlink, resetHyperlink, _ := makeHyperlink(e.URL)
+	hyperlink, resetHyperlink, _ := makeHyperlink(href)
 
 	style := ctx.options.Styles.ImageText
 	if e.TextOnly {
 		style.Format = strings.TrimSuffix(style.Format, " →")
 	}
+	broken := ctx.options.Styles.BrokenLink
+	if !ok {
+		style = brokenLinkStyle(style, broken)
+		_, _ = ctx.renderText(w, ctx.blockStack.With(broken), broken.Prefix)
+		defer func() {
+			_, _ = ctx.renderText(w, ctx.blockStack.With(broken), broken.Suffix)
+		}()
+	}
 
 	if len(e.Text) > 0 {
 		token := hyperlink + e.Text + resetHyperlink
@@ -41,11 +52,15 @@ func (e *ImageElement) Render(w io.Writer, ctx RenderContext) error {
 	}
 
 	if len(e.URL) > 0 {
-		token := hyperlink + resolveRelativeURL(e.BaseURL, e.URL) + resetHyperlink
+		imageStyle := ctx.options.Styles.Image
+		if !ok {
+			imageStyle = brokenLinkStyle(imageStyle, broken)
+		}
+		token := hyperlink + resolveRelativeURL(e.BaseURL, href) + resetHyperlink
 		el := &BaseElement{
 			Token:  token,
 			Prefix: " ",
-			Style:  ctx.options.Styles.Image,
+			Style:  imageStyle,
 		}
 		err := el.Render(w, ctx)
 		if err != nil {
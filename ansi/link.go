@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/url"
 
+	"charm.land/glamour/v2/autolink"
 	"github.com/charmbracelet/x/ansi"
 )
 
@@ -17,16 +18,28 @@ type LinkElement struct {
 	Children []ElementRenderer
 	SkipText bool
 	SkipHref bool
+	// TextStyle overrides the style of the link text.
+	TextStyle *StylePrimitive
 
 	hyperlink, resetHyperlink string
-	validURL                  bool
+	href                      string
+	validURL, broken          bool
 }
 
 // Render renders a LinkElement.
 func (e *LinkElement) Render(w io.Writer, ctx RenderContext) error {
+	var ok bool
+	e.href, ok = ctx.resolveLink(e.URL)
+	e.broken = !ok
+	ctx = ctx.withSpan("Link", e.href)
+
 	// Make OSC 8 hyperlink token.
-	e.hyperlink, e.resetHyperlink, e.validURL = makeHyperlink(e.URL)
+	e.hyperlink, e.resetHyperlink, e.validURL = makeHyperlink(e.href)
 
+	broken := ctx.options.Styles.BrokenLink
+	if e.broken {
+		_, _ = ctx.renderText(w, ctx.blockStack.With(broken), broken.Prefix)
+	}
 	if !e.SkipText {
 		if err := e.renderTextPart(w, ctx); err != nil {
 			return err
@@ -37,14 +50,35 @@ func (e *LinkElement) Render(w io.Writer, ctx RenderContext) error {
 			return err
 		}
 	}
+	if e.broken {
+		_, _ = ctx.renderText(w, ctx.blockStack.With(broken), broken.Suffix)
+	}
 	return nil
 }
 
+// brokenLinkStyle returns the style of a part of a broken link or image.
+// The colors and attributes of the broken link style override the ones of
+// style, which keeps its format and affixes.
+func brokenLinkStyle(style, broken StylePrimitive) StylePrimitive {
+	s := cascadeStylePrimitive(style, broken, false)
+	s.Format = style.Format
+	s.BlockPrefix, s.BlockSuffix = style.BlockPrefix, style.BlockSuffix
+	s.Prefix, s.Suffix = style.Prefix, style.Suffix
+	return s
+}
+
 func (e *LinkElement) renderTextPart(w io.Writer, ctx RenderContext) error {
+	st := ctx.options.Styles.LinkText
+	if e.TextStyle != nil {
+		st = *e.TextStyle
+	}
+	if e.broken {
+		st = brokenLinkStyle(st, ctx.options.Styles.BrokenLink)
+	}
+
 	for _, child := range e.Children {
 		if r, ok := child.(StyleOverriderElementRenderer); ok { //nolint:nestif
 			var b bytes.Buffer
-			st := ctx.options.Styles.LinkText
 			if err := r.StyleOverrideRender(&b, ctx, st); err != nil {
 				return fmt.Errorf("glamour: error rendering with style: %w", err)
 			}
@@ -61,7 +95,7 @@ func (e *LinkElement) renderTextPart(w io.Writer, ctx RenderContext) error {
 			token := e.hyperlink + b.String() + e.resetHyperlink
 			el := &BaseElement{
 				Token: token,
-				Style: ctx.options.Styles.LinkText,
+				Style: st,
 			}
 			if err := el.Render(w, ctx); err != nil {
 				return fmt.Errorf("glamour: error rendering: %w", err)
@@ -78,11 +112,15 @@ func (e *LinkElement) renderHrefPart(w io.Writer, ctx RenderContext) error {
 	}
 
 	if e.validURL {
-		token := e.hyperlink + resolveRelativeURL(e.BaseURL, e.URL) + e.resetHyperlink
+		style := ctx.options.Styles.Link
+		if e.broken {
+			style = brokenLinkStyle(style, ctx.options.Styles.BrokenLink)
+		}
+		token := e.hyperlink + resolveRelativeURL(e.BaseURL, e.href) + e.resetHyperlink
 		el := &BaseElement{
 			Token:  token,
 			Prefix: prefix,
-			Style:  ctx.options.Styles.Link,
+			Style:  style,
 		}
 		if err := el.Render(w, ctx); err != nil {
 			return err
@@ -110,3 +148,12 @@ func makeHyperlink(link string) (string, string, bool) {
 
 	return hyperlink, resetHyperlink, validURL
 }
+
+// shortenURL returns the short form of a URL matching one of the autolink
+// patterns. The configured patterns take precedence over the GitHub ones.
+func shortenURL(ctx RenderContext, u string) (string, bool) {
+	if s, ok := autolink.DetectPatterns(u, ctx.options.AutolinkPatterns); ok {
+		return s, true
+	}
+	return autolink.Detect(u)
+}
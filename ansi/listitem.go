@@ -3,27 +3,202 @@ package ansi
 import (
 	"io"
 	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark/ast"
+	astext "github.com/yuin/goldmark/extension/ast"
+)
+
+// Enumeration formats.
+const (
+	EnumerationDecimal            = "decimal"
+	EnumerationDecimalLeadingZero = "decimal-leading-zero"
+	EnumerationLowerAlpha         = "lower-alpha"
+	EnumerationUpperAlpha         = "upper-alpha"
+	EnumerationLowerRoman         = "lower-roman"
+	EnumerationUpperRoman         = "upper-roman"
 )
 
 // An ItemElement is used to render items inside a list.
 type ItemElement struct {
 	IsOrdered   bool
 	Enumeration uint
+	// Level is the nesting level of the item's list, starting at zero.
+	Level int
+	// Last is the highest enumeration in the item's list.
+	Last uint
 }
 
 // Render renders an ItemElement.
 func (e *ItemElement) Render(w io.Writer, ctx RenderContext) error {
 	var el *BaseElement
+	rules := ctx.options.Styles.List
 	if e.IsOrdered {
+		format := EnumerationDecimal
+		if len(rules.Enumerations) > 0 {
+			format = rules.Enumerations[e.Level%len(rules.Enumerations)]
+		}
+
+		prefix := formatEnumeration(format, e.Enumeration, e.Last)
+		if rules.AlignEnumeration {
+			// right-align the numbers so their suffixes line up
+			width := enumerationWidth(format, e.Last)
+			prefix = strings.Repeat(" ", max(width-ansi.StringWidth(prefix), 0)) + prefix
+		}
+
 		el = &BaseElement{
 			Style:  ctx.options.Styles.Enumeration,
-			Prefix: strconv.FormatInt(int64(e.Enumeration), 10), //nolint: gosec
+			Prefix: prefix,
 		}
 	} else {
 		el = &BaseElement{
 			Style: ctx.options.Styles.Item,
 		}
+		if len(rules.Bullets) > 0 {
+			el.Style.BlockPrefix = rules.Bullets[e.Level%len(rules.Bullets)] + " "
+		}
 	}
 
 	return el.Render(w, ctx)
 }
+
+// formatEnumeration formats the number n of a list whose highest number is
+// last.
+func formatEnumeration(format string, n, last uint) string {
+	switch format {
+	case EnumerationDecimalLeadingZero:
+		s := strconv.FormatUint(uint64(n), 10)
+		return strings.Repeat("0", max(len(strconv.FormatUint(uint64(last), 10))-len(s), 0)) + s
+	case EnumerationLowerAlpha:
+		return strings.ToLower(alpha(n))
+	case EnumerationUpperAlpha:
+		return alpha(n)
+	case EnumerationLowerRoman:
+		return strings.ToLower(roman(n))
+	case EnumerationUpperRoman:
+		return roman(n)
+	default:
+		return strconv.FormatUint(uint64(n), 10)
+	}
+}
+
+// enumerationWidth returns the width of the widest number up to last.
+func enumerationWidth(format string, last uint) int {
+	switch format {
+	case EnumerationLowerRoman, EnumerationUpperRoman:
+		return romanWidth(last)
+	default:
+		return ansi.StringWidth(formatEnumeration(format, last, last))
+	}
+}
+
+// alpha returns n in bijective base-26: A, B, …, Z, AA, AB, ….
+func alpha(n uint) string {
+	if n == 0 {
+		return "0"
+	}
+	var s []byte
+	for n > 0 {
+		n--
+		s = append([]byte{byte('A' + n%26)}, s...) //nolint: gosec
+		n /= 26
+	}
+	return string(s)
+}
+
+var romanNumerals = []struct {
+	value  uint
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+// romanDigitWidths are the widths of the roman numerals for the decimal digits
+// 0 to 9 at any place.
+var romanDigitWidths = [10]int{0, 1, 2, 3, 2, 1, 2, 3, 4, 2}
+
+// romanWidth returns the width of the widest roman numeral up to last. Roman
+// numerals don't get wider with every number: the widest ones either match
+// last up to some digit, have a smaller one there and end in eights, or are
+// last itself.
+func romanWidth(last uint) int {
+	if last == 0 || last >= 4000 {
+		return max(len(roman(min(last, 3888))), len(roman(last)))
+	}
+	digits := [4]int{int(last / 1000), int(last / 100 % 10), int(last / 10 % 10), int(last % 10)} //nolint: gosec
+	var width, prefix int
+	for i, d := range digits {
+		for smaller := range d {
+			width = max(width, prefix+romanDigitWidths[smaller]+romanDigitWidths[8]*(len(digits)-1-i))
+		}
+		prefix += romanDigitWidths[d]
+	}
+	return max(width, prefix)
+}
+
+// roman returns n as a roman numeral. Numbers outside of the range roman
+// numerals can express are returned as decimals.
+func roman(n uint) string {
+	if n == 0 || n >= 4000 {
+		return strconv.FormatUint(uint64(n), 10)
+	}
+	var b strings.Builder
+	for _, r := range romanNumerals {
+		for n >= r.value {
+			b.WriteString(r.symbol)
+			n -= r.value
+		}
+	}
+	return b.String()
+}
+
+// listItemRenderer returns the renderer for the marker of a list item.
+func listItemRenderer(node ast.Node) ElementRenderer {
+	list := node.Parent().(*ast.List)
+
+	if node.FirstChild() != nil &&
+		node.FirstChild().FirstChild() != nil &&
+		node.FirstChild().FirstChild().Kind() == astext.KindTaskCheckBox {
+		nc := node.FirstChild().FirstChild().(*astext.TaskCheckBox)
+		return &TaskElement{
+			Checked: nc.IsChecked,
+		}
+	}
+
+	var l uint
+	var e uint
+	l = 1
+	n := node
+	for n.PreviousSibling() != nil && (n.PreviousSibling().Kind() == ast.KindListItem) {
+		l++
+		n = n.PreviousSibling()
+	}
+	if list.IsOrdered() {
+		e = l
+		if list.Start != 1 {
+			e += uint(list.Start) - 1 //nolint: gosec
+		}
+	}
+
+	var level int
+	for n := list.Parent(); n != nil; n = n.Parent() {
+		if n.Kind() == ast.KindList {
+			level++
+		}
+	}
+
+	last := uint(list.ChildCount()) //nolint: gosec
+	if list.IsOrdered() && list.Start != 1 {
+		last += uint(list.Start) - 1 //nolint: gosec
+	}
+
+	return &ItemElement{
+		IsOrdered:   list.IsOrdered(),
+		Enumeration: e,
+		Level:       level,
+		Last:        last,
+	}
+}
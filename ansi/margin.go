@@ -33,7 +33,7 @@ func NewMarginWriter(ctx RenderContext, w io.Writer, rules StyleBlock) *MarginWr
 	}
 
 	pw := NewPaddingWriter(w, int(bs.Width(ctx)), func(_ io.Writer) { //nolint:gosec
-		_, _ = renderText(w, rules.StylePrimitive, " ")
+		_, _ = ctx.renderText(w, rules.StylePrimitive, " ")
 	})
 
 	ic := " "
@@ -41,7 +41,7 @@ func NewMarginWriter(ctx RenderContext, w io.Writer, rules StyleBlock) *MarginWr
 		ic = *rules.IndentToken
 	}
 	iw := NewIndentWriter(pw, int(indentation+margin), func(_ io.Writer) { //nolint:gosec
-		_, _ = renderText(w, bs.Parent().Style.StylePrimitive, ic)
+		_, _ = ctx.renderText(w, bs.Parent().Style.StylePrimitive, ic)
 	})
 
 	return &MarginWriter{
@@ -217,10 +217,13 @@ func (w *IndentWriter) Write(p []byte) (int, error) {
 
 // Close closes the [IndentWriter].
 func (w *IndentWriter) Close() error {
+	// closing the pen may still write to the underlying writer, so it has to
+	// be closed first
+	perr := w.pw.Close()
 	var werr error
 	if w, ok := w.w.(io.WriteCloser); ok {
 		werr = w.Close()
 	}
 
-	return errors.Join(werr, w.pw.Close())
+	return errors.Join(perr, werr)
 }
@@ -0,0 +1,38 @@
+package ansi
+
+import (
+	"charm.land/glamour/v2/internal/latex"
+	"charm.land/glamour/v2/internal/mark"
+	"github.com/yuin/goldmark/ast"
+)
+
+// newMarkElement returns the element rendering the children of a node as
+// marked text. Superscript and subscript text is written with Unicode
+// characters where they exist.
+func (tr *ANSIRenderer) newMarkElement(t mark.Type, n ast.Node, source []byte) ElementRenderer {
+	ctx := tr.context
+
+	var children []ElementRenderer
+	for nn := n.FirstChild(); nn != nil; nn = nn.NextSibling() {
+		children = append(children, tr.NewElement(nn, source).Renderer)
+	}
+
+	switch t {
+	case mark.Highlight:
+		return &StyledElement{Children: children, Style: ctx.options.Styles.Mark}
+	case mark.Inserted:
+		return &StyledElement{Children: children, Style: ctx.options.Styles.Inserted}
+	}
+
+	style, script := ctx.options.Styles.Superscript, latex.Superscript
+	if t == mark.Subscript {
+		style, script = ctx.options.Styles.Subscript, latex.Subscript
+	}
+	if s, ok := script(plainText(n, source)); ok {
+		style.Prefix, style.Suffix = "", ""
+		children = []ElementRenderer{&BaseElement{Token: s, Style: ctx.options.Styles.Text}}
+	}
+	// the prefix used as a fallback is styled like the text around it
+	style = cascadeStylePrimitive(ctx.blockStack.Current().Style.StylePrimitive, style, false)
+	return &StyledElement{Children: children, Style: style}
+}
@@ -0,0 +1,64 @@
+package ansi
+
+import (
+	"io"
+	"strings"
+
+	"charm.land/glamour/v2/internal/latex"
+)
+
+// A MathInlineElement is used to render math within text.
+type MathInlineElement struct {
+	TeX   string
+	Style StylePrimitive
+}
+
+// Render renders a MathInlineElement. TeX that can't be converted to Unicode
+// is rendered as is.
+func (e *MathInlineElement) Render(w io.Writer, ctx RenderContext) error {
+	s := e.TeX
+	if lines, ok := latex.ToUnicode(e.TeX, false); ok {
+		s = lines[0]
+	}
+	_, _ = ctx.renderText(w, e.Style, e.Style.Prefix+s+e.Style.Suffix)
+	return nil
+}
+
+// A MathBlockElement is used to render display math.
+type MathBlockElement struct {
+	TeX string
+}
+
+// Render renders a MathBlockElement. Fractions and matrices span several
+// lines; TeX that can't be converted to Unicode is rendered as is.
+func (e *MathBlockElement) Render(w io.Writer, ctx RenderContext) error {
+	bs := ctx.blockStack
+	rules := ctx.options.Styles.Math
+
+	var indentation, margin uint
+	if rules.Indent != nil {
+		indentation = *rules.Indent
+	}
+	if rules.Margin != nil {
+		margin = *rules.Margin
+	}
+
+	lines, ok := latex.ToUnicode(e.TeX, true)
+	if !ok {
+		lines = strings.Split(e.TeX, "\n")
+	}
+
+	iw := NewIndentWriter(w, int(indentation+margin), func(_ io.Writer) { //nolint:gosec
+		_, _ = ctx.renderText(w, bs.Current().Style.StylePrimitive, " ")
+	})
+	defer iw.Close() //nolint:errcheck
+
+	style := cascadeStylePrimitives(bs.Current().Style.StylePrimitive, rules.StylePrimitive)
+	_, _ = ctx.renderText(iw, bs.Current().Style.StylePrimitive, rules.BlockPrefix)
+	for _, line := range lines {
+		_, _ = ctx.renderText(iw, style, line)
+		_, _ = io.WriteString(iw, "\n")
+	}
+	_, _ = ctx.renderText(iw, bs.Current().Style.StylePrimitive, rules.BlockSuffix)
+	return nil
+}
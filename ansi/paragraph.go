@@ -27,9 +27,10 @@ func (e *ParagraphElement) Render(w io.Writer, ctx RenderContext) error {
 		Style: cascadeStyle(bs.Current().Style, rules, false),
 	}
 	bs.Push(be)
+	ctx.bidi.paragraph = true
 
-	_, _ = renderText(w, bs.Parent().Style.StylePrimitive, rules.BlockPrefix)
-	_, _ = renderText(bs.Current().Block, bs.Current().Style.StylePrimitive, rules.Prefix)
+	_, _ = ctx.renderText(w, bs.Parent().Style.StylePrimitive, rules.BlockPrefix)
+	_, _ = ctx.renderText(bs.Current().Block, bs.Current().Style.StylePrimitive, rules.Prefix)
 	return nil
 }
 
@@ -45,7 +46,10 @@ func (e *ParagraphElement) Finish(w io.Writer, ctx RenderContext) error {
 		if !ctx.options.PreserveNewLines {
 			blk = strings.ReplaceAll(blk, "\n", " ")
 		}
-		flow := lipgloss.Wrap(blk, int(bs.Width(ctx)), "") //nolint: gosec
+		blk = strings.ReplaceAll(blk, hardBreak, "\n")
+		width := int(bs.Width(ctx)) //nolint: gosec
+		flow := lipgloss.Wrap(blk, width, "")
+		flow = ctx.bidiParagraph(flow, ctx.bidiWidth(), rules.StylePrimitive)
 
 		_, err := io.WriteString(mw, flow)
 		if err != nil {
@@ -54,10 +58,11 @@ func (e *ParagraphElement) Finish(w io.Writer, ctx RenderContext) error {
 		_, _ = io.WriteString(mw, "\n")
 	}
 
-	_, _ = renderText(w, bs.Current().Style.StylePrimitive, rules.Suffix)
-	_, _ = renderText(w, bs.Parent().Style.StylePrimitive, rules.BlockSuffix)
+	_, _ = ctx.renderText(w, bs.Current().Style.StylePrimitive, rules.Suffix)
+	_, _ = ctx.renderText(w, bs.Parent().Style.StylePrimitive, rules.BlockSuffix)
 
 	bs.Current().Block.Reset()
 	bs.Pop()
+	ctx.bidi.paragraph = false
 	return nil
 }
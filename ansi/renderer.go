@@ -6,6 +6,15 @@ import (
 	"net/url"
 	"strings"
 
+	"charm.land/glamour/v2/autolink"
+	"charm.land/glamour/v2/internal/abbr"
+	"charm.land/glamour/v2/internal/details"
+	"charm.land/glamour/v2/internal/frontmatter"
+	"charm.land/glamour/v2/internal/inlinehtml"
+	"charm.land/glamour/v2/internal/latex"
+	"charm.land/glamour/v2/internal/mark"
+	"charm.land/glamour/v2/internal/reference"
+	"github.com/microcosm-cc/bluemonday"
 	east "github.com/yuin/goldmark-emoji/ast"
 	"github.com/yuin/goldmark/ast"
 	astext "github.com/yuin/goldmark/extension/ast"
@@ -18,7 +27,21 @@ type Options struct {
 	BaseURL          string
 	WordWrap         int
 	TableWrap        *bool
+	TableLayout      TableLayout
 	InlineTableLinks bool
+	LinkFooters      LinkFooterScope
+	FrontMatter      FrontMatterMode
+	Details          DetailsMode
+	Abbreviations    AbbreviationMode
+	Diagrams         bool
+	HTMLPolicy       *bluemonday.Policy
+	LinkResolver     LinkResolver
+	Emoji            EmojiOptions
+	Bidi             BidiMode
+	AutolinkPatterns []autolink.Pattern
+	FullAutolinks    bool
+	TOCPosition      TOCPosition
+	TOCMaxDepth      int
 	PreserveNewLines bool
 	Styles           StyleConfig
 	ChromaFormatter  string
@@ -26,7 +49,9 @@ type Options struct {
 
 // ANSIRenderer renders markdown content as ANSI escaped sequences.
 type ANSIRenderer struct { //nolint: revive
-	context RenderContext
+	context     RenderContext
+	frontMatter map[string]any
+	folded      bool
 }
 
 // NewRenderer returns a new ANSIRenderer with style and options set.
@@ -50,6 +75,9 @@ func (r *ANSIRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
 	reg.Register(ast.KindParagraph, r.renderNode)
 	reg.Register(ast.KindTextBlock, r.renderNode)
 	reg.Register(ast.KindThematicBreak, r.renderNode)
+	reg.Register(frontmatter.KindFrontMatter, r.renderNode)
+	reg.Register(latex.KindMathBlock, r.renderNode)
+	reg.Register(details.KindDetails, r.renderNode)
 
 	// inlines
 	reg.Register(ast.KindAutoLink, r.renderNode)
@@ -60,6 +88,11 @@ func (r *ANSIRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
 	reg.Register(ast.KindRawHTML, r.renderNode)
 	reg.Register(ast.KindText, r.renderNode)
 	reg.Register(ast.KindString, r.renderNode)
+	reg.Register(reference.KindReference, r.renderNode)
+	reg.Register(latex.KindInlineMath, r.renderNode)
+	reg.Register(inlinehtml.KindSpan, r.renderNode)
+	reg.Register(mark.KindMark, r.renderNode)
+	reg.Register(abbr.KindAbbreviation, r.renderNode)
 
 	// tables
 	reg.Register(astext.KindTable, r.renderNode)
@@ -97,6 +130,35 @@ func (r *ANSIRenderer) renderNode(w util.BufWriter, source []byte, node ast.Node
 		return ast.WalkContinue, nil
 	}
 
+	if r.context.options.TOCPosition == TOCMarker && isTOCMarker(node, source) {
+		if entering {
+			if node.PreviousSibling() != nil {
+				_, _ = io.WriteString(bs.Current().Block, "\n")
+			}
+			if err := r.renderTOC(bs.Current().Block); err != nil {
+				return ast.WalkStop, err
+			}
+		}
+		return ast.WalkSkipChildren, nil
+	}
+	sm := r.context.sourceMap
+	if entering && node.Type() == ast.TypeDocument {
+		r.prepareFrontMatter(node)
+		r.prepareDetails(node, source)
+		r.prepareHTML(node, source)
+		r.prepareWikiLinks(node)
+		r.context.toc.collect(node, source, r.context.options.Styles.HeadingNumbering)
+		sm.reset(source)
+		*r.context.links = linkFooters{}
+		*r.context.abbreviations = abbreviations{}
+		r.context.spans.spans = nil
+	}
+	// sections end with the links they contain
+	if r.context.options.LinkFooters == LinkFootersSection && entering &&
+		node.Kind() == ast.KindHeading && node.Parent().Type() == ast.TypeDocument {
+		r.printLinkFooters()
+	}
+
 	e := r.NewElement(node, source)
 	if entering { //nolint: nestif
 		// everything below the Document element gets rendered into a block buffer
@@ -105,12 +167,24 @@ func (r *ANSIRenderer) renderNode(w util.BufWriter, source []byte, node ast.Node
 		}
 
 		_, _ = io.WriteString(writeTo, e.Entering)
+		if sm.enabled && bs.Len() > 0 && mapped(node) {
+			sm.enter(node, bs.Current().Block)
+		}
 		if e.Renderer != nil {
-			err := e.Renderer.Render(writeTo, r.context)
+			err := e.Renderer.Render(writeTo, r.context.withNode(node))
 			if err != nil {
 				return ast.WalkStop, fmt.Errorf("glamour: error rendering: %w", err)
 			}
 		}
+
+		if node.Type() == ast.TypeDocument && r.context.options.TOCPosition == TOCTop {
+			if err := r.renderTOC(bs.Current().Block); err != nil {
+				return ast.WalkStop, err
+			}
+			if len(r.context.toc.entries) > 0 {
+				_, _ = io.WriteString(bs.Current().Block, "\n")
+			}
+		}
 	} else {
 		// everything below the Document element gets rendered into a block buffer
 		if bs.Len() > 0 {
@@ -123,11 +197,35 @@ func (r *ANSIRenderer) renderNode(w util.BufWriter, source []byte, node ast.Node
 			writeTo = w
 		}
 
-		if e.Finisher != nil {
-			err := e.Finisher.Finish(writeTo, r.context)
-			if err != nil {
-				return ast.WalkStop, fmt.Errorf("glamour: error finishing render: %w", err)
+		finish := func() error {
+			if e.Finisher != nil {
+				err := e.Finisher.Finish(writeTo, r.context.withNode(node))
+				if err != nil {
+					return fmt.Errorf("glamour: error finishing render: %w", err)
+				}
+			}
+			return nil
+		}
+
+		if node.Type() == ast.TypeDocument {
+			r.printLinkFooters()
+			r.printGlossary()
+		}
+
+		var err error
+		switch {
+		case sm.enabled && node.Type() == ast.TypeDocument:
+			doc := bs.Current().Block
+			if err = finish(); err == nil {
+				sm.finish(doc, strings.Count(r.context.options.Styles.Document.BlockPrefix, "\n"))
 			}
+		case sm.enabled && bs.Len() > 0 && mapped(node):
+			err = sm.exit(bs.Current().Block, finish)
+		default:
+			err = finish()
+		}
+		if err != nil {
+			return ast.WalkStop, err
 		}
 
 		_, _ = io.WriteString(bs.Current().Block, e.Exiting)
@@ -140,7 +238,8 @@ func isChild(node ast.Node) bool {
 	for n := node.Parent(); n != nil; n = n.Parent() {
 		// These types are already rendered by their parent
 		switch n.Kind() {
-		case ast.KindCodeSpan, ast.KindAutoLink, ast.KindLink, ast.KindImage, ast.KindEmphasis, astext.KindStrikethrough, astext.KindTableCell:
+		case ast.KindCodeSpan, ast.KindAutoLink, ast.KindLink, ast.KindImage, ast.KindEmphasis, astext.KindStrikethrough, astext.KindTableCell,
+			inlinehtml.KindSpan, mark.KindMark, abbr.KindAbbreviation:
 			return true
 		}
 	}
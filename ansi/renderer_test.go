@@ -8,6 +8,12 @@ import (
 	"strings"
 	"testing"
 
+	"charm.land/glamour/v2/autolink"
+	"charm.land/glamour/v2/internal/abbr"
+	"charm.land/glamour/v2/internal/frontmatter"
+	"charm.land/glamour/v2/internal/latex"
+	"charm.land/glamour/v2/internal/mark"
+	"charm.land/glamour/v2/internal/wikilink"
 	"github.com/charmbracelet/x/exp/golden"
 	"github.com/yuin/goldmark"
 	emoji "github.com/yuin/goldmark-emoji"
@@ -20,6 +26,7 @@ import (
 const (
 	examplesDir = "../styles/examples/"
 	issuesDir   = "../testdata/issues/"
+	testdataDir = "../testdata/"
 )
 
 func TestRenderer(t *testing.T) {
@@ -144,3 +151,487 @@ func TestRendererIssues(t *testing.T) {
 		})
 	}
 }
+
+func TestRendererTableLayout(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "table_layout.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		layout TableLayout
+		width  int
+	}{
+		{"grid_120", TableLayoutGrid, 120},
+		{"auto_40", TableLayoutAuto, 40},
+		{"auto_80", TableLayoutAuto, 80},
+		{"auto_200", TableLayoutAuto, 200},
+		{"stacked_20", TableLayoutStacked, 20},
+		{"stacked_60", TableLayoutStacked, 60},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			options := Options{
+				WordWrap:    tc.width,
+				TableLayout: tc.layout,
+				Styles:      darkStyle(t),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRendererTableColumns(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "table_columns.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maxWidth := uint(30)
+	minWidth := uint(8)
+	tests := []struct {
+		name     string
+		strategy string
+		min, max *uint
+		width    int
+	}{
+		{"directive", "", nil, nil, 70},
+		{"content", TableWidthContent, nil, &maxWidth, 70},
+		{"equal", TableWidthEqual, nil, nil, 70},
+		{"proportional", TableWidthProportional, &minWidth, &maxWidth, 70},
+		{"nowrap_overflow", "", nil, nil, 30},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			options := Options{
+				WordWrap: tc.width,
+				Styles:   darkStyle(t),
+			}
+			options.Styles.Table.WidthStrategy = tc.strategy
+			options.Styles.Table.MinColumnWidth = tc.min
+			options.Styles.Table.MaxColumnWidth = tc.max
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRendererTableBorders(t *testing.T) {
+	in := []byte("| Name | Value |\n| ---- | ----: |\n| one | 1 |\n| two | 2 |\n| three | 3 |\n")
+
+	outer := true
+	noHeader := ""
+	headerSep := "═"
+	padding := uint(2)
+	stripe := "236"
+	tests := []struct {
+		name  string
+		style func(*StyleTable)
+	}{
+		{"rounded", func(s *StyleTable) {
+			s.Border = "rounded"
+			s.OuterBorder = &outer
+		}},
+		{"double", func(s *StyleTable) {
+			s.Border = "double"
+			s.OuterBorder = &outer
+		}},
+		{"header_separator", func(s *StyleTable) {
+			s.Border = "rounded"
+			s.OuterBorder = &outer
+			s.HeaderSeparator = &headerSep
+		}},
+		{"header_separator_runes", func(s *StyleTable) {
+			s.HeaderSeparator = &headerSep
+			s.BorderRunes = &StyleTableBorder{Top: "e"}
+		}},
+		{"no_header_separator", func(s *StyleTable) {
+			s.HeaderSeparator = &noHeader
+		}},
+		{"custom_runes", func(s *StyleTable) {
+			s.OuterBorder = &outer
+			s.BorderRunes = &StyleTableBorder{
+				Top:    "~",
+				Bottom: "~",
+			}
+		}},
+		{"zebra", func(s *StyleTable) {
+			s.EvenRow.BackgroundColor = &stripe
+			s.CellPadding = &padding
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			options := Options{
+				WordWrap: 40,
+				Styles:   darkStyle(t),
+			}
+			tc.style(&options.Styles.Table)
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRendererTableCells(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "table_cells.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, layout := range map[string]TableLayout{
+		"grid":    TableLayoutGrid,
+		"stacked": TableLayoutStacked,
+	} {
+		t.Run(name, func(t *testing.T) {
+			options := Options{
+				WordWrap:    60,
+				TableLayout: layout,
+				Styles:      darkStyle(t),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func darkStyle(t *testing.T) StyleConfig {
+	t.Helper()
+	return loadStyle(t, "dark")
+}
+
+func loadStyle(t *testing.T, name string) StyleConfig {
+	t.Helper()
+
+	b, err := os.ReadFile("../styles/" + name + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var style StyleConfig
+	if err := json.Unmarshal(b, &style); err != nil {
+		t.Fatal(err)
+	}
+	return style
+}
+
+func TestRendererLinkFooters(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "link_footers.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		scope LinkFooterScope
+	}{
+		{"table", LinkFootersTable},
+		{"section", LinkFootersSection},
+		{"document", LinkFootersDocument},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			options := Options{
+				WordWrap:    60,
+				LinkFooters: tc.scope,
+				Styles:      darkStyle(t),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRendererFrontMatter(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		mode FrontMatterMode
+	}{
+		{"strip", "front_matter.md", FrontMatterStrip},
+		{"render", "front_matter.md", FrontMatterRender},
+		{"title", "front_matter.md", FrontMatterTitle},
+		{"toml", "front_matter.toml.md", FrontMatterRender},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			in, err := os.ReadFile(testdataDir + tc.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			options := Options{
+				WordWrap:    60,
+				FrontMatter: tc.mode,
+				Styles:      darkStyle(t),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRendererMath(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "math.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := Options{
+		WordWrap: 80,
+		Styles:   darkStyle(t),
+	}
+	golden.RequireEqual(t, renderWithOptions(t, options, in))
+}
+
+func TestRendererDiagrams(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "diagrams.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, style := range []string{"dark", "ascii"} {
+		t.Run(style, func(t *testing.T) {
+			options := Options{
+				WordWrap: 80,
+				Diagrams: true,
+				Styles:   loadStyle(t, style),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func renderWithOptions(t *testing.T, options Options, in []byte, extensions ...goldmark.Extender) []byte {
+	t.Helper()
+
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			extension.DefinitionList,
+			emoji.Emoji,
+			frontmatter.New(),
+			latex.New(),
+		),
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+	)
+
+	ar := NewRenderer(options)
+	md.SetRenderer(
+		renderer.NewRenderer(
+			renderer.WithNodeRenderers(util.Prioritized(ar, 1000))))
+
+	var buf bytes.Buffer
+	if err := md.Convert(in, &buf); err != nil {
+		t.Error(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRendererTableOfContents(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "toc.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	title := "Contents"
+	tests := []struct {
+		name     string
+		position TOCPosition
+		maxDepth int
+		numbered bool
+	}{
+		{"top", TOCTop, 0, false},
+		{"marker", TOCMarker, 3, false},
+		{"numbered", TOCMarker, 2, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			options := Options{
+				WordWrap:    60,
+				TOCPosition: tc.position,
+				TOCMaxDepth: tc.maxDepth,
+				Styles:      darkStyle(t),
+			}
+			options.Styles.TableOfContents.Title = &title
+			options.Styles.TableOfContents.Numbered = tc.numbered
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRendererHeadingNumbering(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "toc.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sep := "-"
+	tests := []struct {
+		name      string
+		numbering StyleHeadingNumbering
+	}{
+		{"decimal", StyleHeadingNumbering{}},
+		{"start_level", StyleHeadingNumbering{StartLevel: 2, MaxLevel: 3}},
+		{"formats", StyleHeadingNumbering{
+			StartLevel: 2,
+			Separator:  &sep,
+			Formats:    []string{EnumerationUpperRoman, EnumerationLowerAlpha},
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			options := Options{
+				WordWrap:    60,
+				TOCPosition: TOCMarker,
+				Styles:      darkStyle(t),
+			}
+			options.Styles.HeadingNumbering = &tc.numbering
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRendererInlineHTML(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "inline_html.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, style := range []string{"dark", "ascii"} {
+		t.Run(style, func(t *testing.T) {
+			options := Options{
+				WordWrap: 80,
+				Styles:   loadStyle(t, style),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRendererDetails(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "details.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, mode := range map[string]DetailsMode{
+		"expanded":  DetailsExpanded,
+		"collapsed": DetailsCollapsed,
+	} {
+		t.Run(name, func(t *testing.T) {
+			options := Options{
+				WordWrap: 80,
+				Details:  mode,
+				Styles:   darkStyle(t),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRendererMarks(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "marks.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, style := range []string{"dark", "ascii"} {
+		t.Run(style, func(t *testing.T) {
+			options := Options{
+				WordWrap: 80,
+				Styles:   loadStyle(t, style),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in,
+				mark.New(mark.Highlight),
+				mark.New(mark.Superscript),
+				mark.New(mark.Subscript),
+				mark.New(mark.Inserted),
+			))
+		})
+	}
+}
+
+func TestRendererAbbreviations(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "abbreviations.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, mode := range map[string]AbbreviationMode{
+		"marked":   AbbreviationsMarked,
+		"expanded": AbbreviationsExpanded,
+		"glossary": AbbreviationsGlossary,
+	} {
+		t.Run(name, func(t *testing.T) {
+			options := Options{
+				WordWrap:      80,
+				Abbreviations: mode,
+				Styles:        loadStyle(t, "dark"),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in, abbr.New()))
+		})
+	}
+}
+
+func TestRendererWikiLinks(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "wikilinks.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, style := range []string{"dark", "ascii"} {
+		t.Run(style, func(t *testing.T) {
+			options := Options{
+				BaseURL:      "https://example.com/docs/",
+				WordWrap:     80,
+				LinkResolver: FileResolver{Root: testdataDir},
+				Styles:       loadStyle(t, style),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in, wikilink.New()))
+		})
+	}
+}
+
+func TestRendererAutolinks(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "autolinks.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, full := range map[string]bool{
+		"urls":      true,
+		"shortened": false,
+	} {
+		t.Run(name, func(t *testing.T) {
+			options := Options{
+				WordWrap:         80,
+				AutolinkPatterns: autolink.GitLab(""),
+				FullAutolinks:    full,
+				Styles:           loadStyle(t, "dark"),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRendererBidi(t *testing.T) {
+	in, err := os.ReadFile(testdataDir + "bidi.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, mode := range map[string]BidiMode{
+		"visual":   BidiVisual,
+		"terminal": BidiTerminal,
+	} {
+		t.Run(name, func(t *testing.T) {
+			options := Options{
+				WordWrap: 60,
+				Bidi:     mode,
+				Styles:   loadStyle(t, "dark"),
+			}
+			golden.RequireEqual(t, renderWithOptions(t, options, in))
+		})
+	}
+}
+
+func TestRomanWidth(t *testing.T) {
+	var width int
+	for last := uint(1); last < 4100; last++ {
+		if last < 4000 {
+			width = max(width, len(roman(last)))
+		}
+		if got, want := romanWidth(last), max(width, len(roman(last))); got != want {
+			t.Fatalf("romanWidth(%d) = %d, want %d", last, got, want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package ansi
+
+import (
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"charm.land/glamour/v2/internal/wikilink"
+	"github.com/yuin/goldmark/ast"
+)
+
+// A LinkResolver resolves the destinations of links and images, including
+// the targets of wiki links.
+type LinkResolver interface {
+	// Resolve returns the URL a destination points to, and the text shown
+	// for wiki links without an alias, or "" to show the target. ok is
+	// false for broken links.
+	Resolve(dest string) (href, display string, ok bool)
+}
+
+// FileResolver resolves relative paths and wiki link targets to the files
+// below Root. Wiki link targets without a path match markdown files of the
+// same name anywhere below Root, ignoring case. Destinations are resolved to
+// paths relative to Root, which are resolved against the base URL like any
+// other relative link.
+type FileResolver struct {
+	Root string
+}
+
+// Resolve implements LinkResolver.Resolve.
+func (r FileResolver) Resolve(dest string) (string, string, bool) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		// URLs and anchors are left alone
+		return dest, "", true
+	}
+	name := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+
+	candidates := []string{name}
+	if path.Ext(name) == "" {
+		candidates = append(candidates, name+".md")
+	}
+	for _, c := range candidates {
+		if fi, err := os.Stat(filepath.Join(r.Root, filepath.FromSlash(c))); err == nil && !fi.IsDir() {
+			return r.href(c, u.Fragment), "", true
+		}
+	}
+	if !strings.Contains(name, "/") {
+		if found, ok := r.find(name); ok {
+			return r.href(found, u.Fragment), "", true
+		}
+	}
+	return dest, "", false
+}
+
+// find looks for a markdown file with the given name below Root.
+func (r FileResolver) find(name string) (string, bool) {
+	var found string
+	_ = filepath.WalkDir(r.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".md" {
+			return nil //nolint: nilerr
+		}
+		if !strings.EqualFold(strings.TrimSuffix(d.Name(), ".md"), strings.TrimSuffix(name, ".md")) {
+			return nil
+		}
+		if rel, err := filepath.Rel(r.Root, p); err == nil {
+			found = filepath.ToSlash(rel)
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return found, found != ""
+}
+
+func (r FileResolver) href(p, fragment string) string {
+	u := url.URL{Path: p, Fragment: fragment}
+	return u.String()
+}
+
+// resolveLink resolves the destination of a link or image with the link
+// resolver, if there is one. ok is false for broken links.
+func (ctx RenderContext) resolveLink(dest string) (string, bool) {
+	if ctx.options.LinkResolver == nil || dest == "" {
+		return dest, true
+	}
+	href, _, ok := ctx.options.LinkResolver.Resolve(dest)
+	if !ok || href == "" {
+		return dest, ok
+	}
+	return href, true
+}
+
+// prepareWikiLinks turns wiki links into regular links to their targets.
+// Links without an alias show the text the link resolver returns for their
+// target, if any.
+func (r *ANSIRenderer) prepareWikiLinks(doc ast.Node) {
+	var links []*wikilink.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if l, ok := n.(*wikilink.Node); ok && entering {
+			links = append(links, l)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, n := range links {
+		l := ast.NewLink()
+		l.Destination = []byte(n.Target)
+		var display string
+		if res := r.context.options.LinkResolver; res != nil && !n.HasAlias {
+			_, display, _ = res.Resolve(n.Target)
+		}
+		if display != "" {
+			l.AppendChild(l, ast.NewString([]byte(display)))
+		} else {
+			for c := n.FirstChild(); c != nil; {
+				next := c.NextSibling()
+				l.AppendChild(l, c)
+				c = next
+			}
+		}
+		n.Parent().ReplaceChild(n.Parent(), n, l)
+	}
+}
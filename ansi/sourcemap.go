@@ -0,0 +1,325 @@
+package ansi
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"charm.land/glamour/v2/internal/noderange"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark/ast"
+	astext "github.com/yuin/goldmark/extension/ast"
+)
+
+// A SourceSpan maps a range of rendered lines to the markdown they were
+// rendered from.
+type SourceSpan struct {
+	// Kind is the kind of the markdown node, e.g. "Paragraph".
+	Kind string
+	// Depth is the number of blocks the node is nested in.
+	Depth int
+
+	// StartLine and EndLine are the first and last rendered line of the
+	// node, counting from 0.
+	StartLine, EndLine int
+
+	// Start and End are the byte offsets of the node in the markdown source.
+	// End is exclusive. Nodes without a position of their own, like thematic
+	// breaks, get an empty range at the end of the nodes before them.
+	Start, End int
+	// SourceStartLine and SourceEndLine are the first and last markdown line
+	// of the node, counting from 1.
+	SourceStartLine, SourceEndLine int
+}
+
+// A SourceMap maps rendered lines to the markdown they were rendered from and
+// back. Spans are sorted by their first rendered line, outer blocks first.
+type SourceMap struct {
+	Spans []SourceSpan
+}
+
+// Lookup returns the innermost span containing the given rendered line.
+func (m SourceMap) Lookup(line int) (SourceSpan, bool) {
+	var found *SourceSpan
+	for i, s := range m.Spans {
+		if s.StartLine > line {
+			break
+		}
+		if line <= s.EndLine && (found == nil || s.Depth >= found.Depth) {
+			found = &m.Spans[i]
+		}
+	}
+	if found == nil {
+		return SourceSpan{}, false
+	}
+	return *found, true
+}
+
+// LineForOffset returns the first rendered line of the innermost span
+// containing the given source byte offset.
+func (m SourceMap) LineForOffset(offset int) (int, bool) {
+	return m.reverse(func(s SourceSpan) bool {
+		return offset >= s.Start && offset < s.End
+	})
+}
+
+// LineForSourceLine returns the first rendered line of the innermost span
+// containing the given markdown line, counting from 1.
+func (m SourceMap) LineForSourceLine(line int) (int, bool) {
+	return m.reverse(func(s SourceSpan) bool {
+		return line >= s.SourceStartLine && line <= s.SourceEndLine
+	})
+}
+
+func (m SourceMap) reverse(contains func(SourceSpan) bool) (int, bool) {
+	var found *SourceSpan
+	for i, s := range m.Spans {
+		if contains(s) && (found == nil || s.Depth > found.Depth) {
+			found = &m.Spans[i]
+		}
+	}
+	if found == nil {
+		return 0, false
+	}
+	return found.StartLine, true
+}
+
+// sourceRecord is a block whose position is known relative to the start of
+// the buffer it has been rendered into.
+type sourceRecord struct {
+	node       ast.Node
+	buf        *bytes.Buffer
+	start, end int
+	// pos is the length of buf when the node was entered, if it ended in
+	// the middle of a line, or -1.
+	pos int
+}
+
+// sourceMapper records the rendered position of every block. Blocks are
+// rendered into the buffer of their parent block, so positions are rebased
+// whenever a buffer gets written into its parent's buffer. This accounts for
+// the wrapping done when a block finishes rendering.
+type sourceMapper struct {
+	enabled bool
+	source  []byte
+	open    []sourceRecord
+	records []sourceRecord
+	wraps   map[*bytes.Buffer]wrapping
+	result  SourceMap
+	// nodes are the nodes of the spans of the result.
+	nodes []ast.Node
+}
+
+// wrapping maps the lines of a buffer to the lines they got wrapped into.
+type wrapping struct {
+	starts, ends []int
+	lines        []string
+}
+
+// line returns the wrapped line of the i-th line of the buffer. Since lines
+// can be wrapped into several lines, last selects the last of them.
+func (w wrapping) line(i int, last bool) int {
+	i = min(max(i, 0), len(w.starts)-1)
+	if last {
+		return w.ends[i]
+	}
+	return w.starts[i]
+}
+
+// reset prepares the mapper for a new document.
+func (m *sourceMapper) reset(source []byte) {
+	m.source = source
+	m.open = m.open[:0]
+	m.records = m.records[:0]
+	m.wraps = make(map[*bytes.Buffer]wrapping)
+	m.result = SourceMap{}
+	m.nodes = nil
+}
+
+// wrap records how the lines of buf get wrapped before it's written into its
+// parent's buffer.
+func (m *sourceMapper) wrap(buf *bytes.Buffer, width int, breakpoints string) {
+	if !m.enabled {
+		return
+	}
+
+	var w wrapping
+	for _, line := range strings.Split(buf.String(), "\n") {
+		wrapped := strings.Split(lipgloss.Wrap(line, width, breakpoints), "\n")
+		w.starts = append(w.starts, len(w.lines))
+		w.lines = append(w.lines, wrapped...)
+		w.ends = append(w.ends, len(w.lines)-1)
+	}
+	m.wraps[buf] = w
+}
+
+// mapped reports whether the position of node gets recorded.
+func mapped(node ast.Node) bool {
+	if node.Type() != ast.TypeBlock {
+		return false
+	}
+	// table rows and cells are laid out by lipgloss
+	for n := node.Parent(); n != nil; n = n.Parent() {
+		if n.Kind() == astext.KindTable {
+			return false
+		}
+	}
+	return true
+}
+
+// enter is called before node gets rendered into buf.
+func (m *sourceMapper) enter(node ast.Node, buf *bytes.Buffer) {
+	pos := -1
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		pos = buf.Len()
+	}
+	m.open = append(m.open, sourceRecord{
+		node:  node,
+		buf:   buf,
+		start: bytes.Count(buf.Bytes(), []byte("\n")),
+		pos:   pos,
+	})
+}
+
+// exit is called after node has been rendered. finish renders the node's
+// buffer into the buffer it was entered with.
+func (m *sourceMapper) exit(own *bytes.Buffer, finish func() error) error {
+	r := m.open[len(m.open)-1]
+	m.open = m.open[:len(m.open)-1]
+
+	if own != r.buf {
+		offset := bytes.Count(r.buf.Bytes(), []byte("\n"))
+		if err := finish(); err != nil {
+			return err
+		}
+		m.rebase(own, r.buf, offset)
+	} else if err := finish(); err != nil {
+		return err
+	}
+
+	// a block that starts by ending the line it was entered on, like the
+	// text of an item after a list item's marker, starts on the next line
+	if r.pos >= 0 && r.pos < r.buf.Len() && r.buf.Bytes()[r.pos] == '\n' {
+		r.start++
+	}
+	r.end = bytes.Count(r.buf.Bytes(), []byte("\n"))
+	m.records = append(m.records, r)
+	return nil
+}
+
+// rebase moves the records of a buffer that got written into another buffer
+// at the given line.
+func (m *sourceMapper) rebase(from, to *bytes.Buffer, offset int) {
+	w, wrapped := m.wraps[from]
+	delete(m.wraps, from)
+
+	for i := range m.records {
+		r := &m.records[i]
+		if r.buf != from {
+			continue
+		}
+		if wrapped {
+			r.start, r.end = w.line(r.start, false), w.line(r.end, true)
+		}
+		r.buf = to
+		r.start += offset
+		r.end += offset
+	}
+}
+
+// finish resolves the records of the document's buffer once it has been
+// rendered. offset is the number of lines rendered before the buffer.
+func (m *sourceMapper) finish(doc *bytes.Buffer, offset int) {
+	lines := strings.Split(doc.String(), "\n")
+	if w, ok := m.wraps[doc]; ok {
+		lines = w.lines
+		for i := range m.records {
+			r := &m.records[i]
+			if r.buf == doc {
+				r.start, r.end = w.line(r.start, false), w.line(r.end, true)
+			}
+		}
+	}
+	blank := func(i int) bool {
+		return i < 0 || i >= len(lines) || strings.TrimSpace(ansi.Strip(lines[i])) == ""
+	}
+
+	type nodeSpan struct {
+		SourceSpan
+		node ast.Node
+	}
+	var spans []nodeSpan
+	for _, r := range m.records {
+		if r.buf != doc {
+			continue
+		}
+
+		// blocks start and end with the blank lines separating them
+		start, end := r.start, r.end
+		for start <= end && blank(start) {
+			start++
+		}
+		for end >= start && blank(end) {
+			end--
+		}
+		if start > end {
+			continue
+		}
+
+		s := SourceSpan{
+			Kind:      r.node.Kind().String(),
+			StartLine: start + offset,
+			EndLine:   end + offset,
+		}
+		for n := r.node.Parent(); n != nil && n.Type() != ast.TypeDocument; n = n.Parent() {
+			s.Depth++
+		}
+		s.Start, s.End = noderange.Of(r.node, m.source)
+		if s.Start == s.End {
+			s.Start = precedingEnd(r.node, m.source)
+			s.End = s.Start
+		} else {
+			s.SourceStartLine = bytes.Count(m.source[:s.Start], []byte("\n")) + 1
+			s.SourceEndLine = bytes.Count(m.source[:s.End-1], []byte("\n")) + 1
+		}
+		spans = append(spans, nodeSpan{s, r.node})
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].StartLine != spans[j].StartLine {
+			return spans[i].StartLine < spans[j].StartLine
+		}
+		return spans[i].Depth < spans[j].Depth
+	})
+	m.result = SourceMap{}
+	m.nodes = nil
+	for _, s := range spans {
+		m.result.Spans = append(m.result.Spans, s.SourceSpan)
+		m.nodes = append(m.nodes, s.node)
+	}
+}
+
+// precedingEnd returns the end of the source of the nodes before node.
+func precedingEnd(node ast.Node, source []byte) int {
+	for n := node; n != nil; n = n.Parent() {
+		for p := n.PreviousSibling(); p != nil; p = p.PreviousSibling() {
+			if start, end := noderange.Of(p, source); start < end {
+				return end
+			}
+		}
+	}
+	return 0
+}
+
+// RecordSourceMap enables or disables recording a source map while
+// rendering.
+func (r *ANSIRenderer) RecordSourceMap(enabled bool) {
+	r.context.sourceMap.enabled = enabled
+}
+
+// SourceMap returns the source map of the last rendered document, if
+// recording has been enabled with RecordSourceMap.
+func (r *ANSIRenderer) SourceMap() SourceMap {
+	return r.context.sourceMap.result
+}
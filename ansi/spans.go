@@ -0,0 +1,181 @@
+package ansi
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark/ast"
+)
+
+// A Span is a run of rendered text sharing the same style, link and node.
+type Span struct {
+	Text string
+	// Style is the style the text was rendered with.
+	Style StylePrimitive
+	// Link is the target of the link the text belongs to, if any.
+	Link string
+	// NodeKind is the kind of the markdown node the text was rendered from,
+	// e.g. "Paragraph", "Emphasis", "Strong" or "CodeSpan". It's empty for
+	// text that isn't rendered from a node, like table borders.
+	NodeKind string
+}
+
+// spanMarker starts the escape sequences tying the text following them to a
+// recorded span. They're removed from the output before it's returned.
+const spanMarker = "\x1b]9999;"
+
+// spanRecorder records the style, link and node of the text written while
+// rendering. Every grapheme gets preceded by a marker, which survives
+// wrapping and table layout, and is zero-width like any other escape
+// sequence.
+type spanRecorder struct {
+	enabled bool
+	spans   []Span
+}
+
+// marker records a span and returns the marker of its text.
+func (r *spanRecorder) marker(s Span) string {
+	id := len(r.spans) - 1
+	if id < 0 || !reflect.DeepEqual(r.spans[id], s) {
+		r.spans = append(r.spans, s)
+		id++
+	}
+	return spanMarker + strconv.Itoa(id) + "\a"
+}
+
+// renderText writes styled text, recording its span if spans get recorded.
+func (ctx RenderContext) renderText(w io.Writer, rules StylePrimitive, s string) (int, error) {
+	if !ctx.spans.enabled || s == "" {
+		return renderText(w, rules, s)
+	}
+	return renderMarkedText(w, rules, s, ctx.spans.marker(Span{
+		Style:    rules,
+		Link:     ctx.spanLink,
+		NodeKind: ctx.spanKind,
+	}))
+}
+
+// markSpan marks the graphemes of rendered text that aren't marked yet as
+// belonging to a span of the given style.
+func (ctx RenderContext) markSpan(s string, rules StylePrimitive) string {
+	if !ctx.spans.enabled {
+		return s
+	}
+	return markGraphemes(s, ctx.spans.marker(Span{
+		Style:    rules,
+		Link:     ctx.spanLink,
+		NodeKind: ctx.spanKind,
+	}))
+}
+
+// withNode returns the context for rendering the given node.
+func (ctx RenderContext) withNode(node ast.Node) RenderContext {
+	switch n := node.(type) {
+	case *ast.Text, *ast.String:
+		// text belongs to the node it's part of
+		if node.Parent() != nil {
+			return ctx.withNode(node.Parent())
+		}
+	case *ast.Emphasis:
+		if n.Level > 1 {
+			ctx.spanKind = "Strong"
+			return ctx
+		}
+	}
+	ctx.spanKind = node.Kind().String()
+	return ctx
+}
+
+// withSpan returns the context for rendering text of the given node kind
+// and link.
+func (ctx RenderContext) withSpan(kind, link string) RenderContext {
+	ctx.spanKind = kind
+	if link != "" {
+		ctx.spanLink = link
+	}
+	return ctx
+}
+
+// markGraphemes inserts marker before every grapheme of s that isn't marked
+// yet, except for line breaks.
+func markGraphemes(s, marker string) string {
+	var b strings.Builder
+	var state byte
+	marked := false
+	for len(s) > 0 {
+		seq, _, n, newState := xansi.DecodeSequence(s, state, nil)
+		state = newState
+		switch {
+		case strings.HasPrefix(seq, spanMarker):
+			marked = true
+		case seq[0] == '\x1b' || seq == "\n":
+		case !marked:
+			b.WriteString(marker)
+			fallthrough
+		default:
+			marked = false
+		}
+		b.WriteString(seq)
+		s = s[n:]
+	}
+	return b.String()
+}
+
+// RecordSpans enables or disables recording spans while rendering. While
+// enabled, the output holds markers that need to be removed with Spans.
+func (r *ANSIRenderer) RecordSpans(enabled bool) {
+	r.context.spans.enabled = enabled
+}
+
+// Spans removes the markers from output rendered while recording spans, and
+// returns it along with the spans of each of its lines.
+func (r *ANSIRenderer) Spans(out string) (string, [][]Span) {
+	var plain strings.Builder
+	var lines [][]Span
+	for _, line := range strings.Split(out, "\n") {
+		if len(lines) > 0 {
+			plain.WriteByte('\n')
+		}
+
+		var spans []Span
+		id := -1
+		var state byte
+		for len(line) > 0 {
+			seq, _, n, newState := xansi.DecodeSequence(line, state, nil)
+			state = newState
+			line = line[n:]
+			if m, ok := strings.CutPrefix(seq, spanMarker); ok {
+				id, _ = strconv.Atoi(strings.TrimRight(m, "\a\x1b\\"))
+				continue
+			}
+			plain.WriteString(seq)
+			if seq[0] == '\x1b' {
+				continue
+			}
+
+			var s Span
+			if id >= 0 && id < len(r.context.spans.spans) {
+				s = r.context.spans.spans[id]
+			}
+			id = -1
+			if last := len(spans) - 1; last >= 0 && sameSpan(spans[last], s) {
+				spans[last].Text += seq
+				continue
+			}
+			s.Text = seq
+			spans = append(spans, s)
+		}
+		lines = append(lines, spans)
+	}
+	r.context.spans.spans = nil
+	return plain.String(), lines
+}
+
+// sameSpan reports whether two spans only differ in their text.
+func sameSpan(a, b Span) bool {
+	a.Text, b.Text = "", ""
+	return reflect.DeepEqual(a, b)
+}
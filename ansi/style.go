@@ -44,6 +44,7 @@ type StylePrimitive struct {
 	Color           *string `json:"color,omitempty"`
 	BackgroundColor *string `json:"background_color,omitempty"`
 	Underline       *bool   `json:"underline,omitempty"`
+	UnderlineStyle  string  `json:"underline_style,omitempty"`
 	Bold            *bool   `json:"bold,omitempty"`
 	Upper           *bool   `json:"upper,omitempty"`
 	Lower           *bool   `json:"lower,omitempty"`
@@ -82,15 +83,104 @@ type StyleCodeBlock struct {
 // StyleList holds the style settings for a list.
 type StyleList struct {
 	StyleBlock
-	LevelIndent uint `json:"level_indent,omitempty"`
+	LevelIndent      uint     `json:"level_indent,omitempty"`
+	Bullets          []string `json:"bullets,omitempty"`
+	Enumerations     []string `json:"enumerations,omitempty"`
+	AlignEnumeration bool     `json:"align_enumeration,omitempty"`
+}
+
+// StyleHeadingNumbering holds the settings for numbering headings.
+type StyleHeadingNumbering struct {
+	StartLevel int      `json:"start_level,omitempty"`
+	MaxLevel   int      `json:"max_level,omitempty"`
+	Separator  *string  `json:"separator,omitempty"`
+	Formats    []string `json:"formats,omitempty"`
+}
+
+// StyleTOC holds the style settings for a table of contents.
+type StyleTOC struct {
+	StyleBlock
+	Title       *string        `json:"title,omitempty"`
+	TitleStyle  StylePrimitive `json:"title_style,omitempty"`
+	Entry       StylePrimitive `json:"entry,omitempty"`
+	LevelIndent *uint          `json:"level_indent,omitempty"`
+	Numbered    bool           `json:"numbered,omitempty"`
 }
 
 // StyleTable holds the style settings for a table.
 type StyleTable struct {
 	StyleBlock
-	CenterSeparator *string `json:"center_separator,omitempty"`
-	ColumnSeparator *string `json:"column_separator,omitempty"`
-	RowSeparator    *string `json:"row_separator,omitempty"`
+	CenterSeparator *string           `json:"center_separator,omitempty"`
+	ColumnSeparator *string           `json:"column_separator,omitempty"`
+	RowSeparator    *string           `json:"row_separator,omitempty"`
+	MinColumnWidth  *uint             `json:"min_column_width,omitempty"`
+	MaxColumnWidth  *uint             `json:"max_column_width,omitempty"`
+	WidthStrategy   string            `json:"width_strategy,omitempty"`
+	Border          string            `json:"border,omitempty"`
+	BorderRunes     *StyleTableBorder `json:"border_runes,omitempty"`
+	OuterBorder     *bool             `json:"outer_border,omitempty"`
+	Header          StylePrimitive    `json:"header,omitempty"`
+	HeaderSeparator *string           `json:"header_separator,omitempty"`
+	OddRow          StylePrimitive    `json:"odd_row,omitempty"`
+	EvenRow         StylePrimitive    `json:"even_row,omitempty"`
+	CellPadding     *uint             `json:"cell_padding,omitempty"`
+	Stacked         StyleTableStacked `json:"stacked,omitempty"`
+}
+
+// StyleTableBorder holds custom border runes for a table. Empty positions
+// keep the runes of the table's border.
+type StyleTableBorder struct {
+	Top          string `json:"top,omitempty"`
+	Bottom       string `json:"bottom,omitempty"`
+	Left         string `json:"left,omitempty"`
+	Right        string `json:"right,omitempty"`
+	TopLeft      string `json:"top_left,omitempty"`
+	TopRight     string `json:"top_right,omitempty"`
+	BottomLeft   string `json:"bottom_left,omitempty"`
+	BottomRight  string `json:"bottom_right,omitempty"`
+	Middle       string `json:"middle,omitempty"`
+	MiddleLeft   string `json:"middle_left,omitempty"`
+	MiddleRight  string `json:"middle_right,omitempty"`
+	MiddleTop    string `json:"middle_top,omitempty"`
+	MiddleBottom string `json:"middle_bottom,omitempty"`
+}
+
+// StyleTableStacked holds the style settings for tables rendered in the
+// stacked layout, where each row is printed as a block of "Header: value"
+// lines.
+type StyleTableStacked struct {
+	Label     StylePrimitive `json:"label,omitempty"`
+	Value     StylePrimitive `json:"value,omitempty"`
+	Separator *string        `json:"separator,omitempty"`
+}
+
+// StyleFrontMatter holds the style settings for front matter rendered as a
+// box of keys and values.
+type StyleFrontMatter struct {
+	StyleBlock
+	Key         StylePrimitive `json:"key,omitempty"`
+	Value       StylePrimitive `json:"value,omitempty"`
+	Separator   *string        `json:"separator,omitempty"`
+	Border      string         `json:"border,omitempty"`
+	BorderColor *string        `json:"border_color,omitempty"`
+}
+
+// StyleDiagram holds the style settings for Mermaid diagrams. Border names
+// the border boxes are drawn with; lines and arrows are drawn with ASCII
+// characters if it's made of them.
+type StyleDiagram struct {
+	StyleBlock
+	Border string `json:"border,omitempty"`
+}
+
+// StyleDetails holds the style settings for <details> blocks. The block
+// style applies to their body; Summary styles the disclosure line, which
+// starts with the Open or Closed marker.
+type StyleDetails struct {
+	StyleBlock
+	Summary StylePrimitive `json:"summary,omitempty"`
+	Open    string         `json:"open,omitempty"`
+	Closed  string         `json:"closed,omitempty"`
 }
 
 // StyleConfig is used to configure the styling behavior of an ANSIRenderer.
@@ -108,24 +198,59 @@ type StyleConfig struct {
 	H5      StyleBlock `json:"h5,omitempty"`
 	H6      StyleBlock `json:"h6,omitempty"`
 
+	HeadingNumbering *StyleHeadingNumbering `json:"heading_numbering,omitempty"`
+
+	TableOfContents StyleTOC `json:"table_of_contents,omitempty"`
+
+	FrontMatter StyleFrontMatter `json:"front_matter,omitempty"`
+
 	Text           StylePrimitive `json:"text,omitempty"`
 	Strikethrough  StylePrimitive `json:"strikethrough,omitempty"`
 	Emph           StylePrimitive `json:"emph,omitempty"`
 	Strong         StylePrimitive `json:"strong,omitempty"`
 	HorizontalRule StylePrimitive `json:"hr,omitempty"`
 
+	// Mark and Inserted style ==highlighted== and ++inserted++ text.
+	Mark     StylePrimitive `json:"mark,omitempty"`
+	Inserted StylePrimitive `json:"inserted,omitempty"`
+	// Superscript and Subscript style ^superscript^ and ~subscript~ text.
+	// Prefix and Suffix are only written around text without Unicode
+	// superscript or subscript characters.
+	Superscript StylePrimitive `json:"superscript,omitempty"`
+	Subscript   StylePrimitive `json:"subscript,omitempty"`
+	// Abbreviation styles terms defined by abbreviation definitions, in the
+	// text and in the glossary.
+	Abbreviation StylePrimitive `json:"abbreviation,omitempty"`
+
 	Item        StylePrimitive `json:"item,omitempty"`
 	Enumeration StylePrimitive `json:"enumeration,omitempty"`
 	Task        StyleTask      `json:"task,omitempty"`
 
 	Link     StylePrimitive `json:"link,omitempty"`
 	LinkText StylePrimitive `json:"link_text,omitempty"`
+	// BrokenLink styles links and images the link resolver can't resolve,
+	// on top of their regular style.
+	BrokenLink StylePrimitive `json:"broken_link,omitempty"`
+	// Reference styles references to issues, users and commits, on top of
+	// the link text style.
+	Reference StylePrimitive `json:"reference,omitempty"`
 
 	Image     StylePrimitive `json:"image,omitempty"`
 	ImageText StylePrimitive `json:"image_text,omitempty"`
 
 	Code      StyleBlock     `json:"code,omitempty"`
 	CodeBlock StyleCodeBlock `json:"code_block,omitempty"`
+	// Kbd styles keys written in <kbd> tags.
+	Kbd StylePrimitive `json:"kbd,omitempty"`
+	// Underline styles text written in <u> tags.
+	Underline StylePrimitive `json:"underline,omitempty"`
+
+	// Math styles inline and display math. Prefix and Suffix are only used
+	// for inline math, BlockPrefix, BlockSuffix, Indent and Margin only for
+	// display math.
+	Math StyleBlock `json:"math,omitempty"`
+
+	Diagram StyleDiagram `json:"diagram,omitempty"`
 
 	Table StyleTable `json:"table,omitempty"`
 
@@ -133,6 +258,8 @@ type StyleConfig struct {
 	DefinitionTerm        StylePrimitive `json:"definition_term,omitempty"`
 	DefinitionDescription StylePrimitive `json:"definition_description,omitempty"`
 
+	Details StyleDetails `json:"details,omitempty"`
+
 	HTMLBlock StyleBlock `json:"html_block,omitempty"`
 	HTMLSpan  StyleBlock `json:"html_span,omitempty"`
 }
@@ -159,6 +286,7 @@ func cascadeStylePrimitive(parent, child StylePrimitive, toBlock bool) StylePrim
 	s.Color = parent.Color
 	s.BackgroundColor = parent.BackgroundColor
 	s.Underline = parent.Underline
+	s.UnderlineStyle = parent.UnderlineStyle
 	s.Bold = parent.Bold
 	s.Upper = parent.Upper
 	s.Title = parent.Title
@@ -186,6 +314,9 @@ func cascadeStylePrimitive(parent, child StylePrimitive, toBlock bool) StylePrim
 	if child.Underline != nil {
 		s.Underline = child.Underline
 	}
+	if child.UnderlineStyle != "" {
+		s.UnderlineStyle = child.UnderlineStyle
+	}
 	if child.Bold != nil {
 		s.Bold = child.Bold
 	}
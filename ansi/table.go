@@ -4,12 +4,29 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 
 	"charm.land/lipgloss/v2"
 	"charm.land/lipgloss/v2/table"
+	xansi "github.com/charmbracelet/x/ansi"
 	astext "github.com/yuin/goldmark/extension/ast"
 )
 
+// TableLayout determines how tables are laid out.
+type TableLayout int
+
+// Table layouts.
+const (
+	// TableLayoutGrid renders tables as a grid of rows and columns.
+	TableLayoutGrid TableLayout = iota
+	// TableLayoutAuto renders tables as a grid if the columns fit the
+	// available width, and falls back to the stacked layout otherwise.
+	TableLayoutAuto
+	// TableLayoutStacked renders each row as a block of "Header: value"
+	// lines.
+	TableLayoutStacked
+)
+
 // A TableElement is used to render tables.
 type TableElement struct {
 	lipgloss *table.Table
@@ -18,6 +35,16 @@ type TableElement struct {
 	row      []string
 	source   []byte
 
+	// headers and rows hold every rendered cell of the table, so the
+	// layout can be decided once the whole table is known.
+	headers []string
+	rows    [][]string
+
+	// columnModes holds the per-column overrides of the table, widths the
+	// calculated width of every column.
+	columnModes map[int]columnMode
+	widths      []int
+
 	tableImages []tableLink
 	tableLinks  []tableLink
 }
@@ -32,6 +59,7 @@ type TableHeadElement struct{}
 type TableCellElement struct {
 	Children []ElementRenderer
 	Head     bool
+	Row      int
 }
 
 // Render renders a TableElement.
@@ -49,14 +77,14 @@ func (e *TableElement) Render(w io.Writer, ctx RenderContext) error {
 	}
 
 	iw := NewIndentWriter(w, int(indentation+margin), func(_ io.Writer) { //nolint:gosec
-		_, _ = renderText(w, bs.Current().Style.StylePrimitive, " ")
+		_, _ = ctx.renderText(w, bs.Current().Style.StylePrimitive, " ")
 	})
 	defer iw.Close() //nolint:errcheck
 
 	style := bs.With(rules.StylePrimitive)
 
-	_, _ = renderText(iw, bs.Current().Style.StylePrimitive, rules.BlockPrefix)
-	_, _ = renderText(iw, style, rules.Prefix)
+	_, _ = ctx.renderText(iw, bs.Current().Style.StylePrimitive, rules.BlockPrefix)
+	_, _ = ctx.renderText(iw, style, rules.Prefix)
 	width := int(ctx.blockStack.Width(ctx)) //nolint: gosec
 
 	wrap := true
@@ -72,6 +100,34 @@ func (e *TableElement) Render(w io.Writer, ctx RenderContext) error {
 	return nil
 }
 
+// tableBorders are the named borders a table can be drawn with.
+var tableBorders = map[string]lipgloss.Border{
+	"normal":   lipgloss.NormalBorder(),
+	"rounded":  lipgloss.RoundedBorder(),
+	"thick":    lipgloss.ThickBorder(),
+	"double":   lipgloss.DoubleBorder(),
+	"ascii":    lipgloss.ASCIIBorder(),
+	"hidden":   lipgloss.HiddenBorder(),
+	"markdown": lipgloss.MarkdownBorder(),
+}
+
+// tableRowStyle returns the style of the given row, where the header row is
+// [table.HeaderRow] and data rows start at zero.
+func tableRowStyle(rules StyleTable, row int) StylePrimitive {
+	switch {
+	case row == table.HeaderRow:
+		return rules.Header
+	case row%2 == 0:
+		return rules.OddRow
+	default:
+		return rules.EvenRow
+	}
+}
+
+func hasOuterBorder(ctx RenderContext) bool {
+	return ctx.options.Styles.Table.OuterBorder != nil && *ctx.options.Styles.Table.OuterBorder
+}
+
 func (e *TableElement) setStyles(ctx RenderContext) {
 	docRules := ctx.options.Styles.Document
 	if docRules.BackgroundColor != nil {
@@ -79,15 +135,21 @@ func (e *TableElement) setStyles(ctx RenderContext) {
 		ctx.table.lipgloss.BaseStyle(baseStyle)
 	}
 
-	ctx.table.lipgloss = ctx.table.lipgloss.StyleFunc(func(_, col int) lipgloss.Style {
+	ctx.table.lipgloss = ctx.table.lipgloss.StyleFunc(func(row, col int) lipgloss.Style {
 		st := lipgloss.NewStyle().Inline(false)
 		// Default Styles
-		st = st.Margin(0, 1)
+		st = st.Margin(0, cellMargin(ctx))
 
 		// Override with custom styles
 		if m := ctx.options.Styles.Table.Margin; m != nil {
 			st = st.Padding(0, int(*m)) //nolint: gosec
 		}
+		if bg := tableRowStyle(ctx.options.Styles.Table, row).BackgroundColor; bg != nil {
+			st = st.Background(lipgloss.Color(*bg)).MarginBackground(lipgloss.Color(*bg))
+		}
+		if col < len(e.widths) {
+			st = st.Width(e.widths[col] + cellPadding(ctx))
+		}
 		switch e.table.Alignments[col] {
 		case astext.AlignLeft:
 			st = st.Align(lipgloss.Left).PaddingRight(0)
@@ -103,24 +165,98 @@ func (e *TableElement) setStyles(ctx RenderContext) {
 	})
 }
 
-func (e *TableElement) setBorders(ctx RenderContext) {
+func (e *TableElement) border(ctx RenderContext) lipgloss.Border {
 	rules := ctx.options.Styles.Table
 	border := lipgloss.NormalBorder()
+	if b, ok := tableBorders[rules.Border]; ok {
+		border = b
+	}
 
 	if rules.RowSeparator != nil && rules.ColumnSeparator != nil {
-		border = lipgloss.Border{
-			Top:    *rules.RowSeparator,
-			Bottom: *rules.RowSeparator,
-			Left:   *rules.ColumnSeparator,
-			Right:  *rules.ColumnSeparator,
-			Middle: *rules.CenterSeparator,
+		border.Top = *rules.RowSeparator
+		border.Bottom = *rules.RowSeparator
+		border.Left = *rules.ColumnSeparator
+		border.Right = *rules.ColumnSeparator
+		border.Middle = *rules.CenterSeparator
+	}
+
+	if r := rules.BorderRunes; r != nil {
+		for _, p := range []struct {
+			dst *string
+			src string
+		}{
+			{&border.Top, r.Top},
+			{&border.Bottom, r.Bottom},
+			{&border.Left, r.Left},
+			{&border.Right, r.Right},
+			{&border.TopLeft, r.TopLeft},
+			{&border.TopRight, r.TopRight},
+			{&border.BottomLeft, r.BottomLeft},
+			{&border.BottomRight, r.BottomRight},
+			{&border.Middle, r.Middle},
+			{&border.MiddleLeft, r.MiddleLeft},
+			{&border.MiddleRight, r.MiddleRight},
+			{&border.MiddleTop, r.MiddleTop},
+			{&border.MiddleBottom, r.MiddleBottom},
+		} {
+			if p.src != "" {
+				*p.dst = p.src
+			}
+		}
+	}
+
+	return border
+}
+
+func (e *TableElement) setBorders(ctx RenderContext) {
+	outer := hasOuterBorder(ctx)
+
+	ctx.table.lipgloss.Border(e.border(ctx))
+	ctx.table.lipgloss.BorderTop(outer)
+	ctx.table.lipgloss.BorderLeft(outer)
+	ctx.table.lipgloss.BorderRight(outer)
+	ctx.table.lipgloss.BorderBottom(outer)
+
+	if sep := ctx.options.Styles.Table.HeaderSeparator; sep != nil && *sep == "" {
+		ctx.table.lipgloss.BorderHeader(false)
+	}
+}
+
+// styleHeaderSeparator draws the rule below the header with the configured
+// header separator. Lip Gloss draws it with the top border otherwise.
+func (e *TableElement) styleHeaderSeparator(ctx RenderContext, s string) string {
+	sep := ctx.options.Styles.Table.HeaderSeparator
+	if sep == nil || *sep == "" || len(ctx.table.headers) == 0 {
+		return s
+	}
+
+	border := e.border(ctx)
+	lines := strings.Split(s, "\n")
+	first := 0
+	if hasOuterBorder(ctx) {
+		first++
+	}
+	for i := first; i < len(lines); i++ {
+		if isHeaderSeparator(xansi.Strip(lines[i]), border) {
+			lines[i] = strings.ReplaceAll(lines[i], border.Top, *sep)
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isHeaderSeparator reports whether a line is the rule below the header: a
+// run of top border runes, joined by the middle border runes.
+func isHeaderSeparator(line string, border lipgloss.Border) bool {
+	if border.Top == "" || !strings.Contains(line, border.Top) {
+		return false
+	}
+	for _, r := range []string{border.Top, border.Middle, border.MiddleLeft, border.MiddleRight} {
+		if r != "" {
+			line = strings.ReplaceAll(line, r, "")
 		}
 	}
-	ctx.table.lipgloss.Border(border)
-	ctx.table.lipgloss.BorderTop(false)
-	ctx.table.lipgloss.BorderLeft(false)
-	ctx.table.lipgloss.BorderRight(false)
-	ctx.table.lipgloss.BorderBottom(false)
+	return line == ""
 }
 
 // Finish finishes rendering a TableElement.
@@ -129,20 +265,32 @@ func (e *TableElement) Finish(_ io.Writer, ctx RenderContext) error {
 		ctx.table.lipgloss = nil
 		ctx.table.tableImages = nil
 		ctx.table.tableLinks = nil
+		ctx.table.headers = nil
+		ctx.table.rows = nil
 	}()
 
 	rules := ctx.options.Styles.Table
 
-	e.setStyles(ctx)
-	e.setBorders(ctx)
-
 	ow := ctx.blockStack.Current().Block
-	if _, err := ow.WriteString(ctx.table.lipgloss.String()); err != nil {
-		return fmt.Errorf("glamour: error writing to buffer: %w", err)
+	if e.isStacked(ctx) {
+		e.renderStacked(ow, ctx)
+	} else {
+		if e.hasColumnLayout(ctx) {
+			e.layoutColumns(ctx)
+		}
+		e.setStyles(ctx)
+		e.setBorders(ctx)
+
+		// cells are marked already, which leaves the borders
+		s := e.styleHeaderSeparator(ctx, ctx.table.lipgloss.String())
+		s = ctx.markSpan(s, ctx.blockStack.With(rules.StylePrimitive))
+		if _, err := ow.WriteString(s); err != nil {
+			return fmt.Errorf("glamour: error writing to buffer: %w", err)
+		}
 	}
 
-	_, _ = renderText(ow, ctx.blockStack.With(rules.StylePrimitive), rules.Suffix)
-	_, _ = renderText(ow, ctx.blockStack.Current().Style.StylePrimitive, rules.BlockSuffix)
+	_, _ = ctx.renderText(ow, ctx.blockStack.With(rules.StylePrimitive), rules.Suffix)
+	_, _ = ctx.renderText(ow, ctx.blockStack.Current().Style.StylePrimitive, rules.BlockSuffix)
 
 	e.printTableLinks(ctx)
 
@@ -157,6 +305,7 @@ func (e *TableRowElement) Finish(_ io.Writer, ctx RenderContext) error {
 	}
 
 	ctx.table.lipgloss.Row(ctx.table.row...)
+	ctx.table.rows = append(ctx.table.rows, ctx.table.row)
 	ctx.table.row = []string{}
 	return nil
 }
@@ -168,6 +317,7 @@ func (e *TableHeadElement) Finish(_ io.Writer, ctx RenderContext) error {
 	}
 
 	ctx.table.lipgloss.Headers(ctx.table.header...)
+	ctx.table.headers = ctx.table.header
 	ctx.table.header = []string{}
 	return nil
 }
@@ -175,7 +325,12 @@ func (e *TableHeadElement) Finish(_ io.Writer, ctx RenderContext) error {
 // Render renders a TableCellElement.
 func (e *TableCellElement) Render(_ io.Writer, ctx RenderContext) error {
 	var b bytes.Buffer
-	style := ctx.options.Styles.Table.StylePrimitive
+	rules := ctx.options.Styles.Table
+	row := e.Row
+	if e.Head {
+		row = table.HeaderRow
+	}
+	style := cascadeStylePrimitives(rules.StylePrimitive, tableRowStyle(rules, row))
 	for _, child := range e.Children {
 		if r, ok := child.(StyleOverriderElementRenderer); ok {
 			if err := r.StyleOverrideRender(&b, ctx, style); err != nil {
@@ -196,10 +351,12 @@ func (e *TableCellElement) Render(_ io.Writer, ctx RenderContext) error {
 		}
 	}
 
+	// line breaks at the start and end of a cell would only add empty lines
+	cell := strings.Trim(b.String(), "\n")
 	if e.Head {
-		ctx.table.header = append(ctx.table.header, b.String())
+		ctx.table.header = append(ctx.table.header, cell)
 	} else {
-		ctx.table.row = append(ctx.table.row, b.String())
+		ctx.table.row = append(ctx.table.row, cell)
 	}
 
 	return nil
@@ -0,0 +1,150 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	astext "github.com/yuin/goldmark/extension/ast"
+)
+
+// A TableCellBreakElement is used to render a line break inside a table cell,
+// optionally followed by a list item marker.
+type TableCellBreakElement struct {
+	Marker string
+	Indent int
+	Style  StylePrimitive
+	// Inline breaks are written as a space, since header cells only hold a
+	// single line.
+	Inline bool
+}
+
+// Render renders a TableCellBreakElement.
+func (e *TableCellBreakElement) Render(w io.Writer, ctx RenderContext) error {
+	return e.StyleOverrideRender(w, ctx, StylePrimitive{})
+}
+
+// StyleOverrideRender renders a TableCellBreakElement with a given style.
+func (e *TableCellBreakElement) StyleOverrideRender(w io.Writer, ctx RenderContext, style StylePrimitive) error {
+	// the line break itself must not be styled, or the styles would leak
+	// into the cell's padding
+	br := "\n" + strings.Repeat(" ", e.Indent)
+	if e.Inline {
+		br = " "
+	}
+	if _, err := io.WriteString(w, br); err != nil {
+		return fmt.Errorf("glamour: error writing line break: %w", err)
+	}
+	_, _ = ctx.renderText(w, cascadeStylePrimitives(style, e.Style), e.Marker)
+	return nil
+}
+
+var cellHTMLTag = regexp.MustCompile(`^<\s*(/?)\s*([a-zA-Z]+)[^>]*?(/?)>$`)
+
+// tableCellChildren returns the renderers for the children of a table cell.
+// Since table cells can only hold inline content, line breaks and lists are
+// written as <br> and <ul>/<ol> HTML tags, or as a backslash followed by a
+// space. Header cells are kept to a single line.
+func (tr *ANSIRenderer) tableCellChildren(node ast.Node, source []byte) []ElementRenderer {
+	ctx := tr.context
+
+	var children []ElementRenderer
+	// lists holds the item counter of every open list, or -1 for unordered
+	// lists
+	var lists []int
+
+	for nn := node.FirstChild(); nn != nil; nn = nn.NextSibling() {
+		if n, ok := nn.(*ast.RawHTML); ok { //nolint: nestif
+			m := cellHTMLTag.FindStringSubmatch(strings.TrimSpace(string(n.Text(source)))) //nolint: staticcheck
+			if m != nil {
+				closing := m[1] == "/"
+				switch strings.ToLower(m[2]) {
+				case "br":
+					children = append(children, &TableCellBreakElement{})
+					continue
+				case "ul", "ol":
+					if closing {
+						if len(lists) > 0 {
+							lists = lists[:len(lists)-1]
+						}
+						if len(lists) == 0 {
+							children = append(children, &TableCellBreakElement{})
+						}
+					} else if strings.EqualFold(m[2], "ol") {
+						lists = append(lists, 0)
+					} else {
+						lists = append(lists, -1)
+					}
+					continue
+				case "li":
+					if closing || len(lists) == 0 {
+						continue
+					}
+					el := &TableCellBreakElement{Indent: 2 * (len(lists) - 1)} //nolint: mnd
+					if i := len(lists) - 1; lists[i] >= 0 {
+						lists[i]++
+						el.Marker = fmt.Sprintf("%d%s", lists[i], ctx.options.Styles.Enumeration.BlockPrefix)
+						el.Style = ctx.options.Styles.Enumeration
+					} else {
+						el.Marker = ctx.options.Styles.Item.BlockPrefix
+						el.Style = ctx.options.Styles.Item
+					}
+					el.Style.BlockPrefix = ""
+					children = append(children, el)
+					continue
+				}
+			}
+		}
+
+		el := tr.NewElement(nn, source).Renderer
+		if be, ok := el.(*BaseElement); ok && nn.Kind() == ast.KindText {
+			children = append(children, splitCellLineBreaks(be, nn.NextSibling() != nil)...)
+			continue
+		}
+		children = append(children, el)
+	}
+
+	if node.Parent() != nil && node.Parent().Kind() == astext.KindTableHeader {
+		for _, el := range children {
+			if br, ok := el.(*TableCellBreakElement); ok {
+				br.Inline = true
+			}
+		}
+	}
+	return children
+}
+
+// splitCellLineBreaks splits a text element at every unescaped backslash
+// followed by a space, a hard line break, and at a trailing one if more
+// inline content follows it in the cell. Other backslashes are literal.
+func splitCellLineBreaks(e *BaseElement, more bool) []ElementRenderer {
+	var children []ElementRenderer
+	s := e.Token
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\\' {
+			i++
+			continue
+		}
+		if (i+1 == len(s) && !more) || (i+1 < len(s) && s[i+1] != ' ' && s[i+1] != '\t') {
+			continue
+		}
+
+		el := *e
+		el.Token = strings.TrimRight(s[:i], " \t")
+		children = append(children, &el, &TableCellBreakElement{})
+		s = strings.TrimLeft(s[i+1:], " \t")
+		i = -1
+	}
+	if s == "" && len(children) > 0 {
+		return children
+	}
+
+	el := *e
+	el.Token = s
+	return append(children, &el)
+}
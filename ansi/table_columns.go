@@ -0,0 +1,282 @@
+package ansi
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark/ast"
+)
+
+// Column width strategies.
+const (
+	// TableWidthContent sizes columns by their content, shrinking the widest
+	// columns first when the table doesn't fit.
+	TableWidthContent = "content"
+	// TableWidthProportional distributes the available width proportionally
+	// to the widest cell of each column.
+	TableWidthProportional = "proportional"
+	// TableWidthEqual gives every column the same width.
+	TableWidthEqual = "equal"
+)
+
+// columnMode determines how cells that don't fit their column are handled.
+type columnMode int
+
+const (
+	columnWrap columnMode = iota
+	columnTruncate
+	columnNoWrap
+)
+
+var columnModes = map[string]columnMode{
+	"wrap":     columnWrap,
+	"truncate": columnTruncate,
+	"nowrap":   columnNoWrap,
+}
+
+// columnDirective matches an HTML comment such as
+// <!-- glamour: col2=truncate,col4=nowrap -->.
+var columnDirective = regexp.MustCompile(`^\s*<!--\s*glamour:(.*?)-->\s*$`)
+
+// parseColumnModes reads the per-column overrides from an HTML comment right
+// before the table. Columns are 1-indexed in the comment.
+func parseColumnModes(node ast.Node, source []byte) map[int]columnMode {
+	prev, ok := node.PreviousSibling().(*ast.HTMLBlock)
+	if !ok {
+		return nil
+	}
+
+	var b strings.Builder
+	lines := prev.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		b.Write(line.Value(source))
+	}
+	m := columnDirective.FindStringSubmatch(b.String())
+	if m == nil {
+		return nil
+	}
+
+	modes := make(map[int]columnMode)
+	for _, field := range strings.Split(m[1], ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			continue
+		}
+		col, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(k), "col"))
+		if err != nil || col < 1 {
+			continue
+		}
+		if mode, ok := columnModes[strings.TrimSpace(v)]; ok {
+			modes[col-1] = mode
+		}
+	}
+	return modes
+}
+
+// hasColumnLayout reports whether column widths need to be calculated by
+// glamour instead of being left to lipgloss.
+func (e *TableElement) hasColumnLayout(ctx RenderContext) bool {
+	rules := ctx.options.Styles.Table
+	return rules.WidthStrategy != "" ||
+		rules.MinColumnWidth != nil ||
+		rules.MaxColumnWidth != nil ||
+		len(e.columnModes) > 0
+}
+
+// columnMode returns the mode of the given column.
+func (e *TableElement) columnMode(ctx RenderContext, col int) columnMode {
+	if mode, ok := e.columnModes[col]; ok {
+		return mode
+	}
+	if ctx.options.TableWrap != nil && !*ctx.options.TableWrap {
+		return columnTruncate
+	}
+	return columnWrap
+}
+
+// cellMargin returns the space between a cell's content and the column
+// separators.
+func cellMargin(ctx RenderContext) int {
+	if p := ctx.options.Styles.Table.CellPadding; p != nil {
+		return int(*p) //nolint: gosec
+	}
+	return 1
+}
+
+// cellPadding returns the horizontal space every cell takes up in addition to
+// its content.
+func cellPadding(ctx RenderContext) int {
+	padding := 2 * cellMargin(ctx)
+	if m := ctx.options.Styles.Table.Margin; m != nil {
+		padding += 2 * int(*m) //nolint: gosec
+	}
+	return padding
+}
+
+// tableChromeWidth returns the horizontal space taken up by padding and
+// borders in a table with n columns.
+func tableChromeWidth(ctx RenderContext, n int) int {
+	sepWidth := 1
+	if sep := ctx.options.Styles.Table.ColumnSeparator; sep != nil {
+		sepWidth = xansi.StringWidth(*sep)
+	}
+
+	w := sepWidth*(n-1) + cellPadding(ctx)*n
+	if hasOuterBorder(ctx) {
+		w += 2 * sepWidth
+	}
+	return w
+}
+
+// columnWidths calculates the content width of every column according to the
+// configured strategy, limits and column modes.
+func (e *TableElement) columnWidths(ctx RenderContext) []int {
+	rules := ctx.options.Styles.Table
+	n := len(ctx.table.headers)
+	for _, row := range ctx.table.rows {
+		n = max(n, len(row))
+	}
+	if n == 0 {
+		return nil
+	}
+
+	width := int(ctx.blockStack.Width(ctx)) //nolint: gosec
+	avail := width - tableChromeWidth(ctx, n)
+
+	natural := make([]int, n)
+	words := make([]int, n)
+	measure := func(cells []string) {
+		for i, cell := range cells {
+			natural[i] = max(natural[i], lipgloss.Width(cell))
+			for _, word := range strings.Fields(xansi.Strip(cell)) {
+				words[i] = max(words[i], xansi.StringWidth(word))
+			}
+		}
+	}
+	measure(ctx.table.headers)
+	for _, row := range ctx.table.rows {
+		measure(row)
+	}
+
+	floors := make([]int, n)
+	ceils := make([]int, n)
+	for i := range n {
+		floors[i] = 1
+		if rules.MinColumnWidth != nil {
+			floors[i] = max(floors[i], int(*rules.MinColumnWidth)) //nolint: gosec
+		}
+		ceils[i] = max(avail, floors[i])
+		if rules.MaxColumnWidth != nil {
+			ceils[i] = max(int(*rules.MaxColumnWidth), floors[i]) //nolint: gosec
+		}
+		if e.columnMode(ctx, i) == columnNoWrap {
+			floors[i] = max(floors[i], natural[i])
+			ceils[i] = max(ceils[i], natural[i])
+		}
+	}
+
+	// avoid breaking words apart, as long as the table still fits
+	wordFloors := make([]int, n)
+	var total int
+	for i := range n {
+		wordFloors[i] = min(max(floors[i], words[i]), ceils[i])
+		total += wordFloors[i]
+	}
+	if total <= avail {
+		floors = wordFloors
+	}
+
+	widths := make([]int, n)
+	switch rules.WidthStrategy {
+	case TableWidthEqual:
+		for i := range widths {
+			widths[i] = avail / n
+		}
+	case TableWidthProportional:
+		total = 0
+		for _, w := range natural {
+			total += w
+		}
+		for i, w := range natural {
+			if total > 0 {
+				widths[i] = avail * w / total
+			}
+		}
+	default:
+		copy(widths, natural)
+	}
+	for i := range widths {
+		widths[i] = min(max(widths[i], floors[i]), ceils[i])
+	}
+
+	fitColumns(widths, floors, ceils, avail)
+	return widths
+}
+
+// fitColumns grows the narrowest or shrinks the widest columns until they
+// fill the available width, within the given limits.
+func fitColumns(widths, floors, ceils []int, avail int) {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+
+	for total < avail {
+		j := -1
+		for i, w := range widths {
+			if w < ceils[i] && (j == -1 || w < widths[j]) {
+				j = i
+			}
+		}
+		if j == -1 {
+			break
+		}
+		widths[j]++
+		total++
+	}
+
+	for total > avail {
+		j := -1
+		for i, w := range widths {
+			if w > floors[i] && (j == -1 || w > widths[j]) {
+				j = i
+			}
+		}
+		if j == -1 {
+			break
+		}
+		widths[j]--
+		total--
+	}
+}
+
+// layoutColumns fixes the width of every column and truncates the cells of
+// truncated columns, re-adding the rows to the lipgloss table.
+func (e *TableElement) layoutColumns(ctx RenderContext) {
+	e.widths = e.columnWidths(ctx)
+
+	total := tableChromeWidth(ctx, len(e.widths))
+	for _, w := range e.widths {
+		total += w
+	}
+
+	// wrapping is decided per column, so lipgloss must never truncate
+	ctx.table.lipgloss.Wrap(true)
+	ctx.table.lipgloss.Width(min(total, int(ctx.blockStack.Width(ctx)))) //nolint: gosec
+
+	ctx.table.lipgloss.ClearRows()
+	for _, row := range ctx.table.rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if i < len(e.widths) && e.columnMode(ctx, i) == columnTruncate {
+				cell = xansi.Truncate(strings.ReplaceAll(cell, "\n", " "), e.widths[i], "…")
+			}
+			cells[i] = cell
+		}
+		ctx.table.lipgloss.Row(cells...)
+	}
+}
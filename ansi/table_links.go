@@ -9,7 +9,6 @@ import (
 	"strconv"
 	"strings"
 
-	"charm.land/glamour/v2/internal/autolink"
 	xansi "github.com/charmbracelet/x/ansi"
 	"github.com/charmbracelet/x/exp/slice"
 	"github.com/yuin/goldmark/ast"
@@ -32,11 +31,69 @@ const (
 	linkTypeRegular
 )
 
+// LinkFooterScope determines which links are listed in numbered footers
+// instead of being rendered inline.
+type LinkFooterScope int
+
+const (
+	// LinkFootersTable lists the links of tables below each table.
+	LinkFootersTable LinkFooterScope = iota
+	// LinkFootersSection lists all links at the end of the section they
+	// appear in. Sections end where the next top-level heading starts.
+	LinkFootersSection
+	// LinkFootersDocument lists all links at the end of the document.
+	LinkFootersDocument
+)
+
+// linkFooters holds the links and images to be listed at the end of the
+// current section or document. They're numbered in one list, so their
+// numbers don't clash.
+type linkFooters struct {
+	links []tableLink
+}
+
+// add adds a link or image to the list, unless its URL is listed already,
+// and returns the list.
+func (f *linkFooters) add(tl tableLink) []tableLink {
+	if indexOfURL(f.links, tl.href) == -1 {
+		f.links = append(f.links, tl)
+	}
+	return f.links
+}
+
+// footerLinks returns the list tl gets numbered in, and whether it's listed
+// in a footer instead of being rendered inline.
+func footerLinks(ctx RenderContext, node ast.Node, tl tableLink) ([]tableLink, bool) {
+	if ctx.options.LinkFooters != LinkFootersTable {
+		return ctx.links.add(tl), true
+	}
+	if ctx.options.InlineTableLinks || !isInsideTable(node) {
+		return nil, false
+	}
+	if tl.linkType == linkTypeImage {
+		return ctx.table.tableImages, true
+	}
+	return ctx.table.tableLinks, true
+}
+
+// printLinkFooters lists the links collected since the last footer.
+func (r *ANSIRenderer) printLinkFooters() {
+	f := r.context.links
+	printLinks(r.context, f.links, nil, true)
+	f.links = nil
+}
+
 func (e *TableElement) printTableLinks(ctx RenderContext) {
 	if !e.shouldPrintTableLinks(ctx) {
 		return
 	}
+	printLinks(ctx, ctx.table.tableLinks, ctx.table.tableImages, false)
+}
 
+// printLinks prints numbered lists of links and images. Unless block is set,
+// the lists follow the text on the current line, otherwise they're printed as
+// a block of their own.
+func printLinks(ctx RenderContext, links, images []tableLink, block bool) {
 	w := ctx.blockStack.Current().Block
 	termWidth := int(ctx.blockStack.Width(ctx)) //nolint: gosec
 
@@ -60,22 +117,31 @@ func (e *TableElement) printTableLinks(ctx RenderContext) {
 	}
 
 	renderLinkHref := func(link tableLink, linkText string) {
-		hyperlink, resetHyperlink, _ := makeHyperlink(link.href)
+		href, ok := ctx.resolveLink(link.href)
+		hyperlink, resetHyperlink, _ := makeHyperlink(href)
 
 		style := ctx.options.Styles.Link
 		if link.linkType == linkTypeImage {
 			style = ctx.options.Styles.Image
 		}
+		broken := ctx.options.Styles.BrokenLink
+		if !ok {
+			style = brokenLinkStyle(style, broken)
+			_, _ = ctx.renderText(w, ctx.blockStack.With(broken), broken.Prefix)
+		}
 
 		linkMaxWidth := max(termWidth-xansi.StringWidth(linkText)-1, 0)
-		token := hyperlink + xansi.Truncate(link.href, linkMaxWidth, "…") + resetHyperlink
+		token := hyperlink + xansi.Truncate(href, linkMaxWidth, "…") + resetHyperlink
 
 		el := &BaseElement{Token: token, Style: style}
 		_ = el.Render(w, ctx)
+		if !ok {
+			_, _ = ctx.renderText(w, ctx.blockStack.With(broken), broken.Suffix)
+		}
 	}
 
 	renderString := func(str string) {
-		_, _ = renderText(w, ctx.blockStack.Current().Style.StylePrimitive, str)
+		_, _ = ctx.renderText(w, ctx.blockStack.Current().Style.StylePrimitive, str)
 	}
 
 	paddingFor := func(total, position int) int {
@@ -97,19 +163,24 @@ func (e *TableElement) printTableLinks(ctx RenderContext) {
 		}
 	}
 
-	if len(ctx.table.tableLinks) > 0 {
-		renderString("\n")
+	first := true
+	for _, list := range [][]tableLink{links, images} {
+		if len(list) == 0 {
+			continue
+		}
+		if !block || !first {
+			renderString("\n")
+		}
+		first = false
+		renderList(list)
 	}
-	renderList(ctx.table.tableLinks)
-
-	if len(ctx.table.tableImages) > 0 {
+	if block && !first {
 		renderString("\n")
 	}
-	renderList(ctx.table.tableImages)
 }
 
 func (e *TableElement) shouldPrintTableLinks(ctx RenderContext) bool {
-	if ctx.options.InlineTableLinks {
+	if ctx.options.InlineTableLinks || ctx.options.LinkFooters != LinkFootersTable {
 		return false
 	}
 	if len(ctx.table.tableLinks) == 0 && len(ctx.table.tableImages) == 0 {
@@ -135,7 +206,7 @@ func (e *TableElement) collectLinksAndImages(ctx RenderContext) error {
 				content:  linkDomain(uri),
 				linkType: linkTypeAuto,
 			}
-			if shortned, ok := autolink.Detect(uri); ok {
+			if shortned, ok := shortenURL(ctx, uri); ok {
 				autoLink.content = shortned
 			}
 			links = append(links, autoLink)
@@ -203,6 +274,8 @@ func nodeContent(node ast.Node, source []byte) ([]byte, error) {
 				if _, err := builder.Write(nn.Segment.Value(source)); err != nil {
 					return fmt.Errorf("glamour: error writing text node: %w", err)
 				}
+			case *ast.String:
+				builder.Write(nn.Value)
 			default:
 				if err := traverse(nn); err != nil {
 					return err
@@ -225,10 +298,24 @@ func linkDomain(href string) string {
 	return "link"
 }
 
+// indexOfURL returns the index of the link to href in list, or -1.
+func indexOfURL(list []tableLink, href string) int {
+	return slices.IndexFunc(list, func(l tableLink) bool { return l.href == href })
+}
+
 func linkWithSuffix(tl tableLink, list []tableLink) string {
+	return tl.content + linkSuffix(tl, list)
+}
+
+// linkSuffix returns the number of the link in the list as "[n]". Links
+// missing from the list get the number of the listed link to the same URL.
+func linkSuffix(tl tableLink, list []tableLink) string {
 	index := slices.Index(list, tl)
 	if index == -1 {
-		return tl.content
+		index = indexOfURL(list, tl.href)
+	}
+	if index == -1 {
+		return ""
 	}
-	return fmt.Sprintf("%s[%d]", tl.content, index+1)
+	return fmt.Sprintf("[%d]", index+1)
 }
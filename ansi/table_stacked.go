@@ -0,0 +1,148 @@
+package ansi
+
+import (
+	"io"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+const defaultStackedSeparator = "─"
+
+// isStacked reports whether the table should be rendered in the stacked
+// layout.
+func (e *TableElement) isStacked(ctx RenderContext) bool {
+	if len(ctx.table.rows) == 0 || len(ctx.table.headers) == 0 {
+		return false
+	}
+
+	switch ctx.options.TableLayout {
+	case TableLayoutStacked:
+		return true
+	case TableLayoutAuto:
+		width := int(ctx.blockStack.Width(ctx)) //nolint: gosec
+		return e.minGridWidth(ctx) > width || e.noWrapOverflows(ctx)
+	case TableLayoutGrid:
+		return e.noWrapOverflows(ctx)
+	}
+	return false
+}
+
+// noWrapOverflows reports whether the grid layout doesn't fit the available
+// width without wrapping nowrap columns.
+func (e *TableElement) noWrapOverflows(ctx RenderContext) bool {
+	noWrap := false
+	for _, mode := range e.columnModes {
+		noWrap = noWrap || mode == columnNoWrap
+	}
+	if !noWrap {
+		return false
+	}
+
+	widths := e.columnWidths(ctx)
+	total := tableChromeWidth(ctx, len(widths))
+	for _, w := range widths {
+		total += w
+	}
+	return total > int(ctx.blockStack.Width(ctx)) //nolint: gosec
+}
+
+// minGridWidth returns the narrowest width the grid layout can be rendered
+// in without breaking words apart.
+func (e *TableElement) minGridWidth(ctx RenderContext) int {
+	widths := make([]int, len(ctx.table.headers))
+	measure := func(cells []string) {
+		for i, cell := range cells {
+			if i >= len(widths) {
+				break
+			}
+			for _, word := range strings.Fields(xansi.Strip(cell)) {
+				widths[i] = max(widths[i], xansi.StringWidth(word))
+			}
+		}
+	}
+	measure(ctx.table.headers)
+	for _, row := range ctx.table.rows {
+		measure(row)
+	}
+
+	total := tableChromeWidth(ctx, len(widths))
+	for _, w := range widths {
+		total += w
+	}
+	return total
+}
+
+// renderStacked renders every row as a block of "Header: value" lines,
+// separating the records with a horizontal rule.
+func (e *TableElement) renderStacked(w io.Writer, ctx RenderContext) {
+	bs := ctx.blockStack
+	rules := ctx.options.Styles.Table
+	width := int(bs.Width(ctx)) //nolint: gosec
+
+	style := bs.With(rules.StylePrimitive)
+	labelStyle := cascadeStylePrimitives(style, rules.Stacked.Label)
+	valueStyle := cascadeStylePrimitives(style, rules.Stacked.Value)
+
+	sep := defaultStackedSeparator
+	if rules.Stacked.Separator != nil {
+		sep = *rules.Stacked.Separator
+	} else if rules.RowSeparator != nil {
+		sep = *rules.RowSeparator
+	}
+
+	var labelWidth int
+	for _, h := range ctx.table.headers {
+		labelWidth = max(labelWidth, xansi.StringWidth(h))
+	}
+
+	// wrap values next to their label if there's room, otherwise print them
+	// below the label using the full width
+	valueWidth := width - labelWidth - 2
+	indent := labelWidth + 2
+	if valueWidth < labelWidth {
+		valueWidth = width
+		indent = 0
+	}
+
+	newline := func(i, j int) {
+		if i > 0 || j > 0 {
+			_, _ = io.WriteString(w, "\n")
+		}
+	}
+
+	for i, row := range ctx.table.rows {
+		if i > 0 {
+			newline(i, 0)
+			if sw := xansi.StringWidth(sep); sw > 0 {
+				_, _ = ctx.renderText(w, style, strings.Repeat(sep, width/sw))
+			}
+		}
+
+		for j, header := range ctx.table.headers {
+			var cell string
+			if j < len(row) {
+				cell = strings.TrimSpace(row[j])
+			}
+
+			newline(i, j)
+			pad := strings.Repeat(" ", labelWidth-xansi.StringWidth(header))
+			_, _ = ctx.renderText(w, labelStyle, header+":")
+			if indent == 0 {
+				_, _ = io.WriteString(w, "\n")
+			} else {
+				_, _ = ctx.renderText(w, style, pad+" ")
+			}
+
+			lines := strings.Split(lipgloss.Wrap(cell, valueWidth, ""), "\n")
+			for k, line := range lines {
+				if k > 0 {
+					_, _ = io.WriteString(w, "\n")
+					_, _ = ctx.renderText(w, style, strings.Repeat(" ", indent))
+				}
+				_, _ = ctx.renderText(w, valueStyle, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package ansi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark/ast"
+)
+
+// TOCPosition determines where a table of contents gets rendered.
+type TOCPosition int
+
+const (
+	// TOCNone doesn't render a table of contents.
+	TOCNone TOCPosition = iota
+	// TOCTop renders the table of contents at the top of the document.
+	TOCTop
+	// TOCMarker renders the table of contents in place of a [[toc]] or
+	// <!-- toc --> marker.
+	TOCMarker
+)
+
+const defaultTOCLevelIndent = 2
+
+// A TOCEntry is a heading listed in a table of contents.
+type TOCEntry struct {
+	Level int
+	Text  string
+	ID    string
+	// Number is the heading's section number, if headings are numbered.
+	Number string
+	// Line is the line of the rendered output the heading starts on. Headings
+	// nested in other blocks report the line their outermost block starts on.
+	Line int
+}
+
+// tableOfContents holds the headings of the document being rendered.
+type tableOfContents struct {
+	entries []TOCEntry
+	index   map[ast.Node]int
+}
+
+// collect gathers all headings of the document and numbers them.
+func (t *tableOfContents) collect(doc ast.Node, source []byte, numbering *StyleHeadingNumbering) {
+	t.entries = nil
+	t.index = make(map[ast.Node]int)
+	var counters [h6 + 1]int
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		var id string
+		if v, ok := h.AttributeString("id"); ok {
+			if b, ok := v.([]byte); ok {
+				id = string(b)
+			}
+		}
+		t.index[n] = len(t.entries)
+		t.entries = append(t.entries, TOCEntry{
+			Level:  h.Level,
+			Text:   headingText(h, source),
+			ID:     id,
+			Number: numbering.number(&counters, h.Level),
+			Line:   -1,
+		})
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// number advances the counters by a heading of the given level and returns
+// its section number.
+func (n *StyleHeadingNumbering) number(counters *[h6 + 1]int, level int) string {
+	if n == nil || level < h1 || level > h6 {
+		return ""
+	}
+
+	start := max(n.StartLevel, h1)
+	end := h6
+	if n.MaxLevel > 0 {
+		end = min(n.MaxLevel, h6)
+	}
+	sep := "."
+	if n.Separator != nil {
+		sep = *n.Separator
+	}
+
+	counters[level]++
+	for i := level + 1; i < len(counters); i++ {
+		counters[i] = 0
+	}
+	if level < start || level > end {
+		return ""
+	}
+
+	nums := make([]string, 0, level-start+1)
+	for i := start; i <= level; i++ {
+		format := EnumerationDecimal
+		if len(n.Formats) > 0 {
+			format = n.Formats[(i-start)%len(n.Formats)]
+		}
+		nums = append(nums, formatEnumeration(format, uint(counters[i]), 0)) //nolint: gosec
+	}
+	return strings.Join(nums, sep)
+}
+
+// entry returns the entry of the given heading.
+func (t *tableOfContents) entry(node ast.Node) *TOCEntry {
+	i, ok := t.index[node]
+	if !ok {
+		return nil
+	}
+	return &t.entries[i]
+}
+
+// headingText returns the plain text of a heading.
+func headingText(node ast.Node, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			b.Write(n.Segment.Value(source))
+			if n.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(n.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+var tocMarker = regexp.MustCompile(`(?i)^\s*(\[\[toc\]\]|<!--\s*toc\s*-->)\s*$`)
+
+// isTOCMarker reports whether node is a [[toc]] or <!-- toc --> marker.
+func isTOCMarker(node ast.Node, source []byte) bool {
+	switch node.Kind() {
+	case ast.KindParagraph, ast.KindHTMLBlock:
+	default:
+		return false
+	}
+
+	var b bytes.Buffer
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		b.Write(line.Value(source))
+	}
+	return tocMarker.Match(b.Bytes())
+}
+
+// A TOCElement is used to render a table of contents.
+type TOCElement struct {
+	BlockElement
+	Entries []TOCEntry
+}
+
+// NewTOCElement returns a TOCElement listing the collected headings.
+func NewTOCElement(ctx RenderContext) *TOCElement {
+	return &TOCElement{
+		BlockElement: BlockElement{
+			Block:   &bytes.Buffer{},
+			Style:   cascadeStyle(ctx.blockStack.Current().Style, ctx.options.Styles.TableOfContents.StyleBlock, false),
+			Margin:  true,
+			Newline: true,
+		},
+		Entries: ctx.toc.entries,
+	}
+}
+
+// Render renders a TOCElement.
+func (e *TOCElement) Render(w io.Writer, ctx RenderContext) error {
+	bs := ctx.blockStack
+	rules := ctx.options.Styles.TableOfContents
+
+	if err := e.BlockElement.Render(w, ctx); err != nil {
+		return err
+	}
+
+	maxDepth := ctx.options.TOCMaxDepth
+	if maxDepth <= 0 {
+		maxDepth = h6
+	}
+	levelIndent := uint(defaultTOCLevelIndent)
+	if rules.LevelIndent != nil {
+		levelIndent = *rules.LevelIndent
+	}
+
+	block := bs.Current().Block
+	style := bs.Current().Style.StylePrimitive
+	entryStyle := cascadeStylePrimitives(style, rules.Entry)
+
+	var lines int
+	newline := func() {
+		if lines > 0 {
+			_, _ = io.WriteString(block, "\n")
+		}
+		lines++
+	}
+
+	if rules.Title != nil {
+		newline()
+		_, _ = ctx.renderText(block, cascadeStylePrimitives(style, rules.TitleStyle), *rules.Title)
+	}
+
+	// levels holds the levels of the enclosing entries, counters their
+	// numbers
+	var levels, counters []int
+	for _, entry := range e.Entries {
+		if entry.Level > maxDepth {
+			continue
+		}
+		for len(levels) > 0 && levels[len(levels)-1] >= entry.Level {
+			levels = levels[:len(levels)-1]
+		}
+		depth := len(levels)
+		levels = append(levels, entry.Level)
+		counters = counters[:min(depth+1, len(counters))]
+		for len(counters) <= depth {
+			counters = append(counters, 0)
+		}
+		counters[depth]++
+
+		newline()
+		_, _ = ctx.renderText(block, style, strings.Repeat(" ", depth*int(levelIndent))) //nolint: gosec
+
+		marker := ctx.options.Styles.Item.BlockPrefix
+		switch {
+		case entry.Number != "":
+			// keep the numbers in line with the headings
+			marker = entry.Number + " "
+		case rules.Numbered:
+			nums := make([]string, depth+1)
+			for i := range nums {
+				nums[i] = strconv.Itoa(counters[i])
+			}
+			marker = strings.Join(nums, ".") + ". "
+		}
+		_, _ = ctx.renderText(block, cascadeStylePrimitives(style, ctx.options.Styles.Item), marker)
+
+		var text bytes.Buffer
+		_, _ = ctx.renderText(&text, entryStyle, entry.Text)
+		if entry.ID != "" {
+			target := "#" + entry.ID
+			if ctx.options.BaseURL != "" {
+				target = resolveRelativeURL(ctx.options.BaseURL, target)
+			}
+			_, _ = io.WriteString(block, ansi.SetHyperlink(target)+text.String()+ansi.ResetHyperlink())
+		} else {
+			_, _ = block.Write(text.Bytes())
+		}
+	}
+	return nil
+}
+
+// renderTOC renders the table of contents into w.
+func (r *ANSIRenderer) renderTOC(w io.Writer) error {
+	if len(r.context.toc.entries) == 0 {
+		return nil
+	}
+
+	e := NewTOCElement(r.context)
+	if err := e.Render(w, r.context); err != nil {
+		return fmt.Errorf("glamour: error rendering table of contents: %w", err)
+	}
+	if err := e.Finish(w, r.context); err != nil {
+		return fmt.Errorf("glamour: error rendering table of contents: %w", err)
+	}
+	return nil
+}
+
+// TableOfContents returns the headings of the last rendered document.
+func (r *ANSIRenderer) TableOfContents() []TOCEntry {
+	return append([]TOCEntry(nil), r.context.toc.entries...)
+}
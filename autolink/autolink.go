@@ -0,0 +1,175 @@
+// Package autolink provides functions to detect links to issues, pull
+// requests and commits on GitHub and other services, and format them in a more
+// readable manner.
+package autolink
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A Pattern shortens URLs matching a regular expression.
+type Pattern struct {
+	Regexp *regexp.Regexp
+	// Format returns the short form of a URL, given the submatches of Regexp.
+	Format func(m []string) string
+}
+
+// GitHub are the patterns for issues, pull requests, discussions and commits
+// on github.com. They're used by default.
+var GitHub = []Pattern{
+	{
+		regexp.MustCompile(`^https?://github\.com/([A-z0-9_-]+)/([A-z0-9_-]+)/(issues?|pulls?|discussions?)/([0-9]+)$`),
+		func(m []string) string { return fmt.Sprintf("%s/%s#%s", m[1], m[2], m[4]) },
+	},
+	{
+		regexp.MustCompile(`^https?://github\.com/([A-z0-9_-]+)/([A-z0-9_-]+)/(issues?|pulls?|discussions?)/([0-9]+)#issuecomment-[0-9]+$`),
+		func(m []string) string { return fmt.Sprintf("%s/%s#%s (comment)", m[1], m[2], m[4]) },
+	},
+	{
+		regexp.MustCompile(`^https?://github\.com/([A-z0-9_-]+)/([A-z0-9_-]+)/pulls?/([0-9]+)#discussion_r[0-9]+$`),
+		func(m []string) string { return fmt.Sprintf("%s/%s#%s (comment)", m[1], m[2], m[3]) },
+	},
+	{
+		regexp.MustCompile(`^https?://github\.com/([A-z0-9_-]+)/([A-z0-9_-]+)/pulls?/([0-9]+)#pullrequestreview-[0-9]+$`),
+		func(m []string) string { return fmt.Sprintf("%s/%s#%s (review)", m[1], m[2], m[3]) },
+	},
+	{
+		regexp.MustCompile(`^https?://github\.com/([A-z0-9_-]+)/([A-z0-9_-]+)/discussions/([0-9]+)#discussioncomment-[0-9]+$`),
+		func(m []string) string { return fmt.Sprintf("%s/%s#%s (comment)", m[1], m[2], m[3]) },
+	},
+	{
+		regexp.MustCompile(`^https?://github\.com/([A-z0-9_-]+)/([A-z0-9_-]+)/commit/([A-z0-9]{7,})(#.*)?$`),
+		func(m []string) string { return fmt.Sprintf("%s/%s@%s", m[1], m[2], m[3][:7]) },
+	},
+	{
+		regexp.MustCompile(`^https?://github\.com/([A-z0-9_-]+)/([A-z0-9_-]+)/pulls?/[0-9]+/commits/([A-z0-9]{7,})(#.*)?$`),
+		func(m []string) string { return fmt.Sprintf("%s/%s@%s", m[1], m[2], m[3][:7]) },
+	},
+}
+
+// GitLab returns the patterns for issues, merge requests, epics and commits
+// on a GitLab instance, e.g. "group/project!42" for a merge request. The host
+// defaults to gitlab.com.
+func GitLab(host string) []Pattern {
+	base := hostPrefix(host, "gitlab.com") + `([A-Za-z0-9_.-]+(?:/[A-Za-z0-9_.-]+)*)/([A-Za-z0-9_.-]+)/-/`
+	return []Pattern{
+		{
+			regexp.MustCompile(base + `(issues|work_items)/([0-9]+)(#note_[0-9]+)?$`),
+			func(m []string) string { return fmt.Sprintf("%s/%s#%s", m[1], m[2], m[4]) + comment(m[5]) },
+		},
+		{
+			regexp.MustCompile(base + `merge_requests/([0-9]+)(?:/diffs)?(#note_[0-9]+)?$`),
+			func(m []string) string { return fmt.Sprintf("%s/%s!%s", m[1], m[2], m[3]) + comment(m[4]) },
+		},
+		{
+			regexp.MustCompile(base + `epics/([0-9]+)$`),
+			func(m []string) string { return fmt.Sprintf("%s/%s&%s", m[1], m[2], m[3]) },
+		},
+		{
+			regexp.MustCompile(base + `commit/([A-Fa-f0-9]{7,})(#.*)?$`),
+			func(m []string) string { return fmt.Sprintf("%s/%s@%s", m[1], m[2], m[3][:7]) },
+		},
+	}
+}
+
+// Gitea returns the patterns for issues, pull requests and commits on a Gitea
+// or Forgejo instance. The host defaults to gitea.com; use codeberg.org for
+// Codeberg.
+func Gitea(host string) []Pattern {
+	base := hostPrefix(host, "gitea.com") + `([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)/`
+	return []Pattern{
+		{
+			regexp.MustCompile(base + `(issues|pulls)/([0-9]+)(#issuecomment-[0-9]+)?$`),
+			func(m []string) string { return fmt.Sprintf("%s/%s#%s", m[1], m[2], m[4]) + comment(m[5]) },
+		},
+		{
+			regexp.MustCompile(base + `commit/([A-Fa-f0-9]{7,})(#.*)?$`),
+			func(m []string) string { return fmt.Sprintf("%s/%s@%s", m[1], m[2], m[3][:7]) },
+		},
+	}
+}
+
+// Bitbucket returns the patterns for issues, pull requests and commits on
+// Bitbucket. The host defaults to bitbucket.org.
+func Bitbucket(host string) []Pattern {
+	base := hostPrefix(host, "bitbucket.org") + `([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)/`
+	return []Pattern{
+		{
+			regexp.MustCompile(base + `issues/([0-9]+)(/[^/#?]*)?$`),
+			func(m []string) string { return fmt.Sprintf("%s/%s#%s", m[1], m[2], m[3]) },
+		},
+		{
+			regexp.MustCompile(base + `pull-requests/([0-9]+)(/[^/#?]*)?$`),
+			func(m []string) string { return fmt.Sprintf("%s/%s!%s", m[1], m[2], m[3]) },
+		},
+		{
+			regexp.MustCompile(base + `commits/([A-Fa-f0-9]{7,})(#.*)?$`),
+			func(m []string) string { return fmt.Sprintf("%s/%s@%s", m[1], m[2], m[3][:7]) },
+		},
+	}
+}
+
+// Jira returns the pattern for issues on a Jira instance, which get shortened
+// to their key, e.g. "PROJ-123". If host is empty, any Atlassian Cloud site
+// matches.
+func Jira(host string) []Pattern {
+	prefix := `^https?://[A-Za-z0-9-]+\.atlassian\.net/`
+	if host != "" {
+		prefix = hostPrefix(host, "")
+	}
+	return []Pattern{
+		{
+			regexp.MustCompile(prefix + `browse/([A-Z][A-Z0-9_]+-[0-9]+)(\?focusedCommentId=[0-9]+.*)?$`),
+			func(m []string) string { return m[1] + comment(m[2]) },
+		},
+	}
+}
+
+// Linear returns the pattern for issues on Linear, which get shortened to
+// their identifier, e.g. "ENG-123".
+func Linear() []Pattern {
+	return []Pattern{
+		{
+			regexp.MustCompile(`^https?://linear\.app/[A-Za-z0-9_-]+/issue/([A-Z][A-Z0-9]*-[0-9]+)(/[^/#?]*)?$`),
+			func(m []string) string { return m[1] },
+		},
+	}
+}
+
+// hostPrefix returns the start of a pattern matching URLs on the given host,
+// or on the fallback if host is empty. The host may include a path, for
+// instances that aren't served from the root.
+func hostPrefix(host, fallback string) string {
+	if host == "" {
+		host = fallback
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://"), "/")
+	return `^https?://` + regexp.QuoteMeta(host) + `/`
+}
+
+// comment returns the suffix of links to comments.
+func comment(anchor string) string {
+	if anchor == "" {
+		return ""
+	}
+	return " (comment)"
+}
+
+// Detect checks if the given URL matches any of the GitHub patterns and
+// returns a human-readable formatted string if a match is found.
+func Detect(u string) (string, bool) {
+	return DetectPatterns(u, GitHub)
+}
+
+// DetectPatterns checks if the given URL matches any of the given patterns
+// and returns a human-readable formatted string if a match is found.
+func DetectPatterns(u string, patterns []Pattern) (string, bool) {
+	for _, p := range patterns {
+		if m := p.Regexp.FindStringSubmatch(u); len(m) > 0 {
+			return p.Format(m), true
+		}
+	}
+	return "", false
+}
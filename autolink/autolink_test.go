@@ -0,0 +1,114 @@
+package autolink_test
+
+import (
+	"testing"
+
+	"charm.land/glamour/v2/autolink"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://github.com/owner/repo/issue/123", "owner/repo#123"},
+		{"https://github.com/owner/repo/issues/123", "owner/repo#123"},
+		{"https://github.com/owner/repo/pull/123", "owner/repo#123"},
+		{"https://github.com/owner/repo/pulls/123", "owner/repo#123"},
+		{"https://github.com/owner/repo/discussions/123", "owner/repo#123"},
+
+		{"https://github.com/owner/repo/issue/123#issuecomment-456", "owner/repo#123 (comment)"},
+		{"https://github.com/owner/repo/issues/123#issuecomment-456", "owner/repo#123 (comment)"},
+		{"https://github.com/owner/repo/pull/123#issuecomment-456", "owner/repo#123 (comment)"},
+		{"https://github.com/owner/repo/pulls/123#issuecomment-456", "owner/repo#123 (comment)"},
+
+		{"https://github.com/owner/repo/pull/123#discussion_r456", "owner/repo#123 (comment)"},
+		{"https://github.com/owner/repo/pulls/123#discussion_r456", "owner/repo#123 (comment)"},
+
+		{"https://github.com/owner/repo/pull/123#pullrequestreview-456", "owner/repo#123 (review)"},
+		{"https://github.com/owner/repo/pulls/123#pullrequestreview-456", "owner/repo#123 (review)"},
+
+		{"https://github.com/owner/repo/discussions/123#discussioncomment-456", "owner/repo#123 (comment)"},
+
+		{"https://github.com/owner/repo/commit/abcdefghijklmnopqrsxyz", "owner/repo@abcdefg"},
+
+		{"https://github.com/owner/repo/pull/123/commits/abcdefghijklmnopqrsxyz", "owner/repo@abcdefg"},
+		{"https://github.com/owner/repo/pulls/123/commits/abcdefghijklmnopqrsxyz", "owner/repo@abcdefg"},
+
+		{"https://github.com/owner/repo/commit/abcdefghijklmnopqrsxyz#diff-123", "owner/repo@abcdefg"},
+		{"https://github.com/owner/repo/pull/123/commits/abcdefghijklmnopqrsxyz#diff-123", "owner/repo@abcdefg"},
+		{"https://github.com/owner/repo/pulls/123/commits/abcdefghijklmnopqrsxyz#diff-123", "owner/repo@abcdefg"},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			result, ok := autolink.Detect(test.url)
+			if !ok {
+				t.Errorf("expected to detect URL, got nil")
+			}
+			if result != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestDetectPatterns(t *testing.T) {
+	var patterns []autolink.Pattern
+	for _, p := range [][]autolink.Pattern{
+		autolink.GitLab(""),
+		autolink.GitLab("https://git.example.com/"),
+		autolink.Gitea("codeberg.org"),
+		autolink.Bitbucket(""),
+		autolink.Jira(""),
+		autolink.Jira("jira.example.com"),
+		autolink.Linear(),
+	} {
+		patterns = append(patterns, p...)
+	}
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://gitlab.com/group/proj/-/issues/42", "group/proj#42"},
+		{"https://gitlab.com/group/sub/proj/-/issues/42#note_123", "group/sub/proj#42 (comment)"},
+		{"https://gitlab.com/group/proj/-/merge_requests/42", "group/proj!42"},
+		{"https://gitlab.com/group/proj/-/merge_requests/42/diffs", "group/proj!42"},
+		{"https://gitlab.com/group/proj/-/commit/abcdef0123456789", "group/proj@abcdef0"},
+		{"https://git.example.com/group/proj/-/merge_requests/7", "group/proj!7"},
+
+		{"https://codeberg.org/owner/repo/issues/12", "owner/repo#12"},
+		{"https://codeberg.org/owner/repo/pulls/12#issuecomment-34", "owner/repo#12 (comment)"},
+		{"https://codeberg.org/owner/repo/commit/abcdef0123456789", "owner/repo@abcdef0"},
+
+		{"https://bitbucket.org/team/repo/issues/5/crash-on-start", "team/repo#5"},
+		{"https://bitbucket.org/team/repo/pull-requests/6", "team/repo!6"},
+		{"https://bitbucket.org/team/repo/commits/abcdef0123456789", "team/repo@abcdef0"},
+
+		{"https://acme.atlassian.net/browse/PROJ-123", "PROJ-123"},
+		{"https://jira.example.com/browse/OPS-9", "OPS-9"},
+
+		{"https://linear.app/acme/issue/ENG-123/fix-the-thing", "ENG-123"},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			result, ok := autolink.DetectPatterns(test.url, patterns)
+			if !ok {
+				t.Errorf("expected to detect URL, got nil")
+			}
+			if result != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+
+	for _, u := range []string{
+		"https://gitlab.com/group/proj",
+		"https://example.com/browse/PROJ-123",
+		"https://git.example.com.evil.com/group/proj/-/issues/1",
+	} {
+		if result, ok := autolink.DetectPatterns(u, patterns); ok {
+			t.Errorf("expected %s not to be detected, got %s", u, result)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package glamour
+
+import (
+	"strings"
+
+	"charm.land/glamour/v2/ansi"
+)
+
+// A Document is rendered markdown broken down into lines of styled spans.
+type Document struct {
+	Lines []Line
+	// Folds are the bodies of <details> blocks, when rendered with
+	// WithDetails. Lines of collapsed folds are still part of Lines, but left
+	// out by String and Visible.
+	Folds []Fold
+}
+
+// A Fold is a range of lines that can be collapsed and expanded.
+type Fold = ansi.Fold
+
+// A Line is a single rendered line.
+type Line struct {
+	Spans []Span
+
+	raw string
+}
+
+// A Span is a run of text sharing the same style, link and node.
+type Span = ansi.Span
+
+// String returns the document as an ANSI string, exactly like Render does.
+func (d *Document) String() string {
+	visible := d.Visible()
+	lines := make([]string, len(visible))
+	for i, l := range visible {
+		lines[i] = l.raw
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Visible returns the lines that aren't part of a collapsed fold.
+func (d *Document) Visible() []Line {
+	hidden := make([]bool, len(d.Lines))
+	for _, f := range d.Folds {
+		if !f.Collapsed {
+			continue
+		}
+		for i := f.Start; i <= f.End && i < len(hidden); i++ {
+			hidden[i] = true
+		}
+	}
+
+	var lines []Line
+	for i, l := range d.Lines {
+		if !hidden[i] {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// Toggle collapses the i-th fold if it's expanded and expands it otherwise,
+// updating the marker of its disclosure line.
+func (d *Document) Toggle(i int) {
+	f := &d.Folds[i]
+	from, to := f.Open, f.Closed
+	if f.Collapsed {
+		from, to = to, from
+	}
+	f.Collapsed = !f.Collapsed
+
+	l := &d.Lines[f.Line]
+	l.raw = strings.Replace(l.raw, from, to, 1)
+	for j, s := range l.Spans {
+		if strings.Contains(s.Text, from) {
+			l.Spans[j].Text = strings.Replace(s.Text, from, to, 1)
+			break
+		}
+	}
+}
+
+// String returns the line as an ANSI string.
+func (l Line) String() string {
+	return l.raw
+}
+
+// Text returns the text of the line without any styling.
+func (l Line) Text() string {
+	var b strings.Builder
+	for _, s := range l.Spans {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+// RenderDocument returns the markdown rendered into a Document. The bodies
+// of collapsed <details> blocks are rendered as well, as collapsed folds.
+func (tr *TermRenderer) RenderDocument(in string) (*Document, error) {
+	tr.ar.RenderFolded(true)
+	tr.ar.RecordSpans(true)
+	defer func() {
+		tr.ar.RenderFolded(false)
+		tr.ar.RecordSpans(false)
+	}()
+
+	out, sm, err := tr.RenderWithSourceMap(in)
+	if err != nil {
+		return nil, err
+	}
+	out, spans := tr.ar.Spans(out)
+
+	doc := &Document{Folds: tr.ar.Folds()}
+	for i, raw := range strings.Split(out, "\n") {
+		line := Line{Spans: spans[i], raw: raw}
+		// text that isn't rendered from a node of its own, like margins and
+		// table borders, belongs to the block it's part of
+		if span, ok := sm.Lookup(i); ok {
+			for j := range line.Spans {
+				if line.Spans[j].NodeKind == "" {
+					line.Spans[j].NodeKind = span.Kind
+				}
+			}
+		}
+		doc.Lines = append(doc.Lines, line)
+	}
+	return doc, nil
+}
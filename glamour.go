@@ -10,14 +10,23 @@ import (
 	"io"
 	"os"
 
+	"github.com/microcosm-cc/bluemonday"
 	"github.com/yuin/goldmark"
 	emoji "github.com/yuin/goldmark-emoji"
+	"github.com/yuin/goldmark-emoji/definition"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/util"
 
 	"charm.land/glamour/v2/ansi"
+	"charm.land/glamour/v2/autolink"
+	"charm.land/glamour/v2/internal/abbr"
+	"charm.land/glamour/v2/internal/frontmatter"
+	"charm.land/glamour/v2/internal/latex"
+	"charm.land/glamour/v2/internal/mark"
+	"charm.land/glamour/v2/internal/reference"
+	"charm.land/glamour/v2/internal/wikilink"
 	styles "charm.land/glamour/v2/styles"
 )
 
@@ -34,8 +43,17 @@ type TermRendererOption func(*TermRenderer) error
 type TermRenderer struct {
 	md          goldmark.Markdown
 	ansiOptions ansi.Options
+	ar          *ansi.ANSIRenderer
 	buf         bytes.Buffer
 	renderBuf   bytes.Buffer
+	// frontMatter is set if front matter gets parsed.
+	frontMatter bool
+
+	// stream renders blocks as they're written when streaming, streamOut
+	// receives them and streamed is the number of lines written so far.
+	stream    *IncrementalRenderer
+	streamOut io.Writer
+	streamed  int
 }
 
 // Render initializes a new TermRenderer and renders a markdown with a specific
@@ -85,11 +103,11 @@ func NewTermRenderer(options ...TermRendererOption) (*TermRenderer, error) {
 			return nil, err
 		}
 	}
-	ar := ansi.NewRenderer(tr.ansiOptions)
+	tr.ar = ansi.NewRenderer(tr.ansiOptions)
 	tr.md.SetRenderer(
 		renderer.NewRenderer(
 			renderer.WithNodeRenderers(
-				util.Prioritized(ar, highPriority),
+				util.Prioritized(tr.ar, highPriority),
 			),
 		),
 	)
@@ -187,6 +205,61 @@ func WithTableWrap(tableWrap bool) TermRendererOption {
 	}
 }
 
+// WithTableLayout sets how tables are laid out. By default, tables are
+// rendered as a grid. With [ansi.TableLayoutAuto], tables that don't fit the
+// word wrap width are rendered as stacked "Header: value" records instead.
+func WithTableLayout(layout ansi.TableLayout) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.ansiOptions.TableLayout = layout
+		return nil
+	}
+}
+
+// WithTableOfContents renders a table of contents linking to the document's
+// headings, either at the top of the document or in place of a [[toc]] or
+// <!-- toc --> marker. Headings deeper than maxDepth are left out; a maxDepth
+// of 0 includes all headings.
+func WithTableOfContents(position ansi.TOCPosition, maxDepth int) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.ansiOptions.TOCPosition = position
+		tr.ansiOptions.TOCMaxDepth = maxDepth
+		return nil
+	}
+}
+
+// WithLinkFooters lists links in numbered footers at the end of each table,
+// section or the whole document, depending on scope. Links are rendered as
+// their text followed by their number, e.g. "text[1]", and are listed once
+// per URL.
+func WithLinkFooters(scope ansi.LinkFooterScope) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.ansiOptions.LinkFooters = scope
+		return nil
+	}
+}
+
+// WithAutolinkPatterns adds patterns for shortening autolinks, e.g. links to
+// issues on a GitLab instance. They're checked before the built-in GitHub
+// patterns. See the autolink package for patterns for common services.
+func WithAutolinkPatterns(patterns ...autolink.Pattern) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.ansiOptions.AutolinkPatterns = append(tr.ansiOptions.AutolinkPatterns, patterns...)
+		return nil
+	}
+}
+
+// WithShortenedAutolinks sets whether autolinks in prose matching the autolink
+// patterns, like links to GitHub issues, get rendered in their short form. By
+// default, they are; the URL is then only kept as a hyperlink, so disable it
+// for terminals not supporting them. Links in table footers are always
+// shortened.
+func WithShortenedAutolinks(shortenedAutolinks bool) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.ansiOptions.FullAutolinks = !shortenedAutolinks
+		return nil
+	}
+}
+
 // WithInlineTableLinks forces tables to render links inline. By default,links
 // are rendered as a list of links at the bottom of the table.
 func WithInlineTableLinks(inlineTableLinks bool) TermRendererOption {
@@ -212,6 +285,187 @@ func WithEmoji() TermRendererOption {
 	}
 }
 
+// WithEmojiOptions sets a TermRenderer's emoji rendering, like WithEmoji,
+// with custom shortcodes, an allow-list of GitHub shortcodes, a fallback for
+// terminals without an emoji font and a width-safe mode.
+func WithEmojiOptions(opts ansi.EmojiOptions) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		emoji.New(emoji.WithEmojis(emojiDefinitions(opts))).Extend(tr.md)
+		tr.ansiOptions.Emoji = opts
+		return nil
+	}
+}
+
+// emojiDefinitions returns the emoji parsed with the given options. Custom
+// emoji take precedence over the GitHub ones.
+func emojiDefinitions(opts ansi.EmojiOptions) definition.Emojis {
+	var custom []definition.Emoji
+	for name, s := range opts.Custom {
+		custom = append(custom, definition.NewEmoji(name, []rune(s), name))
+	}
+	defs := definition.NewEmojis(custom...)
+
+	github := definition.Github()
+	if len(opts.Allow) == 0 {
+		defs.Add(github)
+		return defs
+	}
+	var allowed []definition.Emoji
+	for _, name := range opts.Allow {
+		if e, ok := github.Get(name); ok {
+			allowed = append(allowed, definition.NewEmoji(e.Name, e.Unicode, name))
+		}
+	}
+	defs.Add(definition.NewEmojis(allowed...))
+	return defs
+}
+
+// RepoContext is the repository references like #123 are resolved against.
+type RepoContext = reference.RepoContext
+
+// WithReferenceLinks turns references to issues and pull requests (#123 and
+// owner/repo#123), users (@user) and commit SHAs into links pointing to the
+// given repository's host. References in code aren't touched.
+func WithReferenceLinks(repo RepoContext) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		reference.New(repo).Extend(tr.md)
+		return nil
+	}
+}
+
+// WithFrontMatter parses YAML ("---") and TOML ("+++") front matter at the
+// start of documents instead of rendering it as markdown. mode determines
+// whether it gets left out, rendered as a box of keys and values, or whether
+// its title field gets rendered as a top-level heading. The parsed fields are
+// returned by RenderWithMeta.
+func WithFrontMatter(mode ansi.FrontMatterMode) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		frontmatter.New().Extend(tr.md)
+		tr.ansiOptions.FrontMatter = mode
+		tr.frontMatter = true
+		return nil
+	}
+}
+
+// WithDetails renders <details> blocks as a disclosure line holding their
+// summary, followed by their indented body. In collapsed mode, the body of
+// blocks without the open attribute is left out; RenderDocument records it as
+// a collapsed fold instead.
+func WithDetails(mode ansi.DetailsMode) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.ansiOptions.Details = mode
+		return nil
+	}
+}
+
+// WithDiagrams draws Mermaid flowcharts and sequence diagrams in code blocks
+// as text. Other diagrams, and diagrams too wide for the word wrap, are shown
+// as code.
+func WithDiagrams() TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.ansiOptions.Diagrams = true
+		return nil
+	}
+}
+
+// WithMath renders $inline$ and $$display$$ math. TeX is converted to
+// Unicode where possible, with display math laid out over several lines;
+// anything else is shown as is.
+func WithMath() TermRendererOption {
+	return func(tr *TermRenderer) error {
+		latex.New().Extend(tr.md)
+		return nil
+	}
+}
+
+// WithHTMLPolicy sets the policy HTML the renderer doesn't interpret is
+// sanitized with. By default, all tags are stripped.
+func WithHTMLPolicy(policy *bluemonday.Policy) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.ansiOptions.HTMLPolicy = policy
+		return nil
+	}
+}
+
+// WithMark renders ==text== as highlighted text.
+func WithMark() TermRendererOption {
+	return func(tr *TermRenderer) error {
+		mark.New(mark.Highlight).Extend(tr.md)
+		return nil
+	}
+}
+
+// WithSuperscript renders ^text^ as superscript, using Unicode superscript
+// characters where they exist.
+func WithSuperscript() TermRendererOption {
+	return func(tr *TermRenderer) error {
+		mark.New(mark.Superscript).Extend(tr.md)
+		return nil
+	}
+}
+
+// WithSubscript renders ~text~ as subscript, using Unicode subscript
+// characters where they exist. Text enclosed by single tildes is no longer
+// struck out.
+func WithSubscript() TermRendererOption {
+	return func(tr *TermRenderer) error {
+		mark.New(mark.Subscript).Extend(tr.md)
+		return nil
+	}
+}
+
+// WithInserted renders ++text++ as inserted text.
+func WithInserted() TermRendererOption {
+	return func(tr *TermRenderer) error {
+		mark.New(mark.Inserted).Extend(tr.md)
+		return nil
+	}
+}
+
+// WithAbbreviations parses abbreviation definitions like
+// "*[HTML]: Hyper Text Markup Language" and marks every occurrence of the
+// terms they define outside of code. The mode determines whether the
+// expansions are shown after the first occurrence of a term, in a glossary at
+// the end of the document, or not at all.
+func WithAbbreviations(mode ansi.AbbreviationMode) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		abbr.New().Extend(tr.md)
+		tr.ansiOptions.Abbreviations = mode
+		return nil
+	}
+}
+
+// WithWikiLinks renders wiki-style [[Page Name]] and [[Page Name|alias]]
+// links as links to their targets, which are resolved with the link resolver.
+func WithWikiLinks() TermRendererOption {
+	return func(tr *TermRenderer) error {
+		wikilink.New().Extend(tr.md)
+		return nil
+	}
+}
+
+// WithLinkResolver sets the resolver the destinations of links and images are
+// resolved with. Links it can't resolve are styled as broken links. Use
+// ansi.FileResolver to resolve links to the files in a directory.
+func WithLinkResolver(resolver ansi.LinkResolver) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.ansiOptions.LinkResolver = resolver
+		return nil
+	}
+}
+
+// WithBidi sets how right-to-left text, like Arabic and Hebrew, is rendered.
+// In visual mode, the lines of paragraphs are reordered for terminals without
+// bidi support and right-to-left paragraphs are right-aligned; in terminal
+// mode, right-to-left paragraphs are marked for terminals reordering text
+// themselves. Code spans are always laid out left-to-right.
+func WithBidi(mode ansi.BidiMode) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.ansiOptions.Bidi = mode
+		return nil
+	}
+}
+
 // WithChromaFormatter sets a TermRenderer's chroma formatter used for code blocks.
 func WithChromaFormatter(formatter string) TermRendererOption {
 	return func(tr *TermRenderer) error {
@@ -220,6 +474,25 @@ func WithChromaFormatter(formatter string) TermRendererOption {
 	}
 }
 
+// WithStreaming makes a TermRenderer render each top-level block as soon as
+// it's been written to it, instead of waiting for Close. The rendered blocks
+// are written to w, or can be retrieved by calling Read if w is nil.
+//
+// The output is the same as if the whole document had been rendered at once.
+// Blocks using full or collapsed reference links, like [text][label], are
+// held back until the links have been defined, or until Close is called.
+// Shortcut reference links, like [label], aren't told apart from brackets in
+// text, so they're only rendered as links if defined before the blocks using
+// them have been written.
+func WithStreaming(w io.Writer) TermRendererOption {
+	return func(tr *TermRenderer) error {
+		tr.stream = &IncrementalRenderer{tr: tr}
+		tr.stream.Reset()
+		tr.streamOut = w
+		return nil
+	}
+}
+
 // WithOptions sets multiple TermRenderer options within a single TermRendererOption.
 func WithOptions(options ...TermRendererOption) TermRendererOption {
 	return func(tr *TermRenderer) error {
@@ -244,6 +517,16 @@ func (tr *TermRenderer) Read(b []byte) (int, error) {
 }
 
 func (tr *TermRenderer) Write(b []byte) (int, error) {
+	if tr.stream != nil {
+		if err := tr.stream.write(b); err != nil {
+			return 0, fmt.Errorf("glamour: error rendering markdown: %w", err)
+		}
+		if err := tr.flushStream(tr.stream.lines, false); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
 	n, err := tr.buf.Write(b)
 	if err != nil {
 		return 0, fmt.Errorf("glamour: error writing bytes: %w", err)
@@ -254,6 +537,17 @@ func (tr *TermRenderer) Write(b []byte) (int, error) {
 // Close must be called after writing to TermRenderer. You can then retrieve
 // the rendered markdown by calling Read.
 func (tr *TermRenderer) Close() error {
+	if tr.stream != nil {
+		defer func() {
+			tr.stream.Reset()
+			tr.streamed = 0
+		}()
+		if _, err := tr.stream.Append(""); err != nil {
+			return fmt.Errorf("glamour: error rendering markdown: %w", err)
+		}
+		return tr.flushStream(tr.stream.Lines(), true)
+	}
+
 	err := tr.md.Convert(tr.buf.Bytes(), &tr.renderBuf)
 	if err != nil {
 		return fmt.Errorf("glamour: error converting markdown: %w", err)
@@ -263,6 +557,26 @@ func (tr *TermRenderer) Close() error {
 	return nil
 }
 
+// flushStream writes the rendered lines that haven't been written yet. Lines
+// are terminated by a newline, except for the last line of the document.
+func (tr *TermRenderer) flushStream(lines []string, last bool) error {
+	var w io.Writer = &tr.renderBuf
+	if tr.streamOut != nil {
+		w = tr.streamOut
+	}
+
+	for ; tr.streamed < len(lines); tr.streamed++ {
+		line := lines[tr.streamed]
+		if !last || tr.streamed < len(lines)-1 {
+			line += "\n"
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return fmt.Errorf("glamour: error writing bytes: %w", err)
+		}
+	}
+	return nil
+}
+
 // Render returns the markdown rendered into a string.
 func (tr *TermRenderer) Render(in string) (string, error) {
 	b, err := tr.RenderBytes([]byte(in))
@@ -276,6 +590,62 @@ func (tr *TermRenderer) RenderBytes(in []byte) ([]byte, error) {
 	return buf.Bytes(), err
 }
 
+// RenderWithMeta returns the markdown rendered into a string, along with the
+// fields of its front matter. Front matter is only parsed with the
+// WithFrontMatter option; the fields are nil if there's none.
+func (tr *TermRenderer) RenderWithMeta(in string) (string, map[string]any, error) {
+	out, err := tr.Render(in)
+	if err != nil {
+		return "", nil, err
+	}
+	return out, tr.ar.FrontMatter(), nil
+}
+
+// SourceMap maps rendered lines to the markdown they were rendered from.
+type SourceMap = ansi.SourceMap
+
+// RenderWithSourceMap returns the markdown rendered into a string, along with
+// a map from the rendered lines to the markdown source and back.
+func (tr *TermRenderer) RenderWithSourceMap(in string) (string, SourceMap, error) {
+	out, sm, err := tr.renderWithSourceMap([]byte(in))
+	return string(out), sm, err
+}
+
+func (tr *TermRenderer) renderWithSourceMap(in []byte, opts ...parser.ParseOption) ([]byte, SourceMap, error) {
+	tr.ar.RecordSourceMap(true)
+	defer tr.ar.RecordSourceMap(false)
+
+	var buf bytes.Buffer
+	err := tr.md.Convert(in, &buf, opts...)
+	return buf.Bytes(), tr.ar.SourceMap(), err
+}
+
+// TOCEntry is a heading listed in a table of contents.
+type TOCEntry = ansi.TOCEntry
+
+// TableOfContents returns the headings of a markdown document, along with the
+// line each of them starts on when rendered with the default options.
+func TableOfContents(markdown string) []TOCEntry {
+	tr, err := NewTermRenderer()
+	if err != nil {
+		return nil
+	}
+	entries, err := tr.TableOfContents(markdown)
+	if err != nil {
+		return nil
+	}
+	return entries
+}
+
+// TableOfContents renders the markdown and returns its headings, along with
+// the line each of them starts on in the rendered output.
+func (tr *TermRenderer) TableOfContents(in string) ([]TOCEntry, error) {
+	if _, err := tr.Render(in); err != nil {
+		return nil, err
+	}
+	return tr.ar.TableOfContents(), nil
+}
+
 func getEnvironmentStyle() string {
 	glamourStyle := os.Getenv("GLAMOUR_STYLE")
 	if len(glamourStyle) == 0 {
@@ -6,12 +6,18 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
 
+	gansi "charm.land/glamour/v2/ansi"
+	"charm.land/glamour/v2/autolink"
 	"charm.land/glamour/v2/styles"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/charmbracelet/x/exp/golden"
+	"github.com/microcosm-cc/bluemonday"
 )
 
 const markdown = "testdata/readme.markdown.in"
@@ -46,6 +52,112 @@ func TestTermRendererWriter(t *testing.T) {
 	golden.RequireEqual(t, b)
 }
 
+func TestTermRendererStreaming(t *testing.T) {
+	in, err := os.ReadFile(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	r, err := NewTermRenderer(
+		WithStandardStyle(styles.DarkStyle),
+		WithStreaming(&out),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	half := len(in) / 2
+	if _, err := r.Write(in[:half]); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() == 0 {
+		t.Error("no output before Close")
+	}
+	if _, err := r.Write(in[half:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile("testdata/TestTermRendererWriter.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != string(expected) {
+		t.Errorf("streamed output differs from the rendered document:\n%s", out.String())
+	}
+}
+
+func TestTermRendererStreamingReferences(t *testing.T) {
+	r, err := NewTermRenderer(
+		WithStandardStyle(styles.AsciiStyle),
+		WithStreaming(nil),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := []string{
+		"1. One\n\nA [reference][ref] link.\n\n",
+		"Some more text.\n\n",
+		"[ref]: https://charm.land\n",
+	}
+	var got []byte
+	for i, chunk := range chunks {
+		if _, err := r.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 && bytes.Contains(out, []byte("reference")) {
+			t.Errorf("block with undefined reference got rendered: %q", out)
+		}
+		got = append(got, out...)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, out...)
+
+	expected, err := Render(strings.Join(chunks, ""), styles.AsciiStyle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestTermRendererStreamingBrackets(t *testing.T) {
+	r, err := NewTermRenderer(
+		WithStandardStyle(styles.AsciiStyle),
+		WithStreaming(nil),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Write([]byte("Some [bracketed] text.\n\nMore text.\n\n")); err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out, []byte("bracketed")) {
+		t.Errorf("block with brackets got held back: %q", out)
+	}
+}
+
 func TestTermRenderer(t *testing.T) {
 	r, err := NewTermRenderer(
 		WithStandardStyle("dark"),
@@ -89,6 +201,63 @@ func TestWithEmoji(t *testing.T) {
 	}
 }
 
+func TestWithEmojiOptions(t *testing.T) {
+	in := ":+1: :shipit: :smile: :sunny: :rocket: \u2600\ufe0f sun"
+	for _, tc := range []struct {
+		name string
+		opts gansi.EmojiOptions
+		want string
+	}{
+		{"default", gansi.EmojiOptions{}, "\U0001f44d :shipit: \U0001f604 \u2600\ufe0f \U0001f680"},
+		{"custom", gansi.EmojiOptions{Custom: map[string]string{"shipit": "[ship it]", "+1": "+1"}}, "+1 [ship it] \U0001f604"},
+		{"allow", gansi.EmojiOptions{Allow: []string{"smile"}}, ":+1: :shipit: \U0001f604 :sunny: :rocket:"},
+		{"shortcode", gansi.EmojiOptions{Fallback: gansi.EmojiShortcode}, ":+1: :shipit: :smile: :sunny: :rocket:"},
+		{"ascii", gansi.EmojiOptions{Fallback: gansi.EmojiASCII}, "(y) :shipit: :) :sunny: :rocket:"},
+		{"width safe", gansi.EmojiOptions{WidthSafe: true}, "\U0001f604 \u2600 \U0001f680 \u2600 sun"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewTermRenderer(WithEmojiOptions(tc.opts), WithStandardStyle(styles.NoTTYStyle))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := r.Render(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, tc.want) {
+				t.Errorf("expected %q in output:\n%s", tc.want, out)
+			}
+		})
+	}
+}
+
+func TestWithBidi(t *testing.T) {
+	in := "שלום `code` עולם"
+	for _, tc := range []struct {
+		name string
+		mode gansi.BidiMode
+		want string
+	}{
+		{"none", gansi.BidiNone, "שלום code עולם"},
+		{"visual", gansi.BidiVisual, "םלוע code םולש"},
+		{"terminal", gansi.BidiTerminal, "\x1b[2 k"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewTermRenderer(WithBidi(tc.mode), WithStandardStyle(styles.NoTTYStyle))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := r.Render(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, tc.want) {
+				t.Errorf("expected %q in output:\n%q", tc.want, out)
+			}
+		})
+	}
+}
+
 func TestWithPreservedNewLines(t *testing.T) {
 	r, err := NewTermRenderer(
 		WithPreservedNewLines(),
@@ -281,6 +450,694 @@ func ExampleASCIIStyleConfig() {
 	// ...Cell.5...............................|.Cell.6..............................
 }
 
+func TestTableOfContents(t *testing.T) {
+	in, err := os.ReadFile("testdata/toc.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := TableOfContents(string(in))
+	if len(entries) != 8 {
+		t.Fatalf("expected 8 entries, got %d", len(entries))
+	}
+	if e := entries[3]; e.Level != 3 || e.Text != "From source" || e.ID != "from-source" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	r, err := NewTermRenderer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.Render(string(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out, "\n")
+	for _, e := range entries {
+		if e.Line < 0 || e.Line >= len(lines) || !strings.Contains(lines[e.Line], e.Text) {
+			t.Errorf("heading %q not found on line %d", e.Text, e.Line)
+		}
+	}
+}
+
+func TestRenderWithSourceMap(t *testing.T) {
+	in, err := os.ReadFile("testdata/sourcemap.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewTermRenderer(
+		WithStandardStyle(styles.DarkStyle),
+		WithWordWrap(40),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, sm, err := r.RenderWithSourceMap(string(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the source map must not change the output
+	plain, err := r.Render(string(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != plain {
+		t.Error("output differs from Render")
+	}
+
+	lines := strings.Split(out, "\n")
+	tests := []struct {
+		text       string
+		kind       string
+		sourceLine int
+	}{
+		{"Title", "Heading", 1},
+		{"output.", "Paragraph", 3},
+		{"wrap around", "TextBlock", 5},
+		{"nested", "TextBlock", 7},
+		{"around the line here", "Paragraph", 9},
+		{"1 ", "Table", 11},
+		{"fmt.Println", "FencedCodeBlock", 16},
+	}
+	for _, tc := range tests {
+		line := -1
+		for i, l := range lines {
+			if strings.Contains(ansi.Strip(l), tc.text) {
+				line = i
+				break
+			}
+		}
+		if line == -1 {
+			t.Fatalf("%q not found in output", tc.text)
+		}
+
+		span, ok := sm.Lookup(line)
+		if !ok {
+			t.Errorf("no span for line %d (%q)", line, tc.text)
+			continue
+		}
+		if span.Kind != tc.kind || span.SourceStartLine > tc.sourceLine || span.SourceEndLine < tc.sourceLine {
+			t.Errorf("line %d (%q): unexpected span %+v", line, tc.text, span)
+		}
+
+		back, ok := sm.LineForSourceLine(tc.sourceLine)
+		if !ok || back > line || back < span.StartLine {
+			t.Errorf("source line %d: expected rendered line %d, got %d", tc.sourceLine, span.StartLine, back)
+		}
+	}
+	// tables span their whole rows, pipes included
+	for _, span := range sm.Spans {
+		if span.Kind != "Table" {
+			continue
+		}
+		if src := string(in[span.Start:span.End]); !strings.HasPrefix(src, "|") || !strings.HasSuffix(src, "|") {
+			t.Errorf("expected the table span to cover its rows, got %q", src)
+		}
+	}
+}
+
+func TestRenderDocument(t *testing.T) {
+	in, err := os.ReadFile(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewTermRenderer(
+		WithStandardStyle(styles.DarkStyle),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := r.RenderDocument(string(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.Render(string(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.String() != out {
+		t.Fatal("document doesn't reproduce the rendered output")
+	}
+
+	var links, headings int
+	for i, line := range strings.Split(out, "\n") {
+		if got, want := doc.Lines[i].Text(), ansi.Strip(line); got != want {
+			t.Errorf("line %d: expected text %q, got %q", i, want, got)
+		}
+		for _, span := range doc.Lines[i].Spans {
+			if span.Link != "" {
+				links++
+			}
+			if span.NodeKind == "Heading" && span.Style.Bold != nil && *span.Style.Bold {
+				headings++
+			}
+		}
+	}
+	if links == 0 {
+		t.Error("expected spans with links")
+	}
+	if headings == 0 {
+		t.Error("expected bold heading spans")
+	}
+}
+
+func TestRenderDocumentFolds(t *testing.T) {
+	in, err := os.ReadFile("testdata/details.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewTermRenderer(
+		WithStandardStyle(styles.DarkStyle),
+		WithDetails(gansi.DetailsCollapsed),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := r.RenderDocument(string(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.Render(string(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.String() != out {
+		t.Fatalf("document doesn't reproduce the rendered output:\n%s\n%s", doc.String(), out)
+	}
+
+	var collapsed []bool
+	for _, f := range doc.Folds {
+		collapsed = append(collapsed, f.Collapsed)
+	}
+	if want := []bool{true, false, true, true, true, false}; !slices.Equal(collapsed, want) {
+		t.Fatalf("expected folds collapsed %v, got %v", want, collapsed)
+	}
+
+	doc.Toggle(0)
+	f := doc.Folds[0]
+	if got := ansi.Strip(doc.Lines[f.Line].String()); !strings.Contains(got, "▼ Installation") {
+		t.Errorf("expected expanded marker, got %q", got)
+	}
+	if got := doc.Lines[f.Start].Text(); !strings.Contains(got, "Run the installer") {
+		t.Errorf("expected body after the disclosure line, got %q", got)
+	}
+	if !strings.Contains(ansi.Strip(doc.String()), "Run the installer") {
+		t.Error("expected expanded fold to be visible")
+	}
+
+	expanded, err := NewTermRenderer(
+		WithStandardStyle(styles.DarkStyle),
+		WithDetails(gansi.DetailsExpanded),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Toggle(2)
+	doc.Toggle(3)
+	doc.Toggle(4)
+	want, err := expanded.Render(string(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.String() != want {
+		t.Errorf("expected expanded folds to match the expanded output:\n%s\n%s", doc.String(), want)
+	}
+}
+
+func TestRenderDocumentSpans(t *testing.T) {
+	r, err := NewTermRenderer(WithStandardStyle(styles.DarkStyle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := r.RenderDocument("Some *emphasis*, **strong** and `code` text, and [a link](https://charm.sh).")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := map[string]Span{}
+	for _, line := range doc.Lines {
+		for _, s := range line.Spans {
+			spans[strings.TrimSpace(s.Text)] = s
+		}
+	}
+	style := styles.DarkStyleConfig
+	for _, tc := range []struct {
+		text, kind, link string
+		check            func(gansi.StylePrimitive) bool
+	}{
+		{"emphasis", "Emphasis", "", func(s gansi.StylePrimitive) bool { return s.Italic != nil && *s.Italic }},
+		{"strong", "Strong", "", func(s gansi.StylePrimitive) bool { return s.Bold != nil && *s.Bold }},
+		{"code", "CodeSpan", "", func(s gansi.StylePrimitive) bool {
+			return s.BackgroundColor != nil && *s.BackgroundColor == *style.Code.BackgroundColor
+		}},
+		{"a link", "Link", "https://charm.sh", func(s gansi.StylePrimitive) bool {
+			return s.Color != nil && *s.Color == *style.LinkText.Color
+		}},
+		{"text, and", "Paragraph", "", func(s gansi.StylePrimitive) bool {
+			return s.Color != nil && *s.Color == *style.Document.Color
+		}},
+	} {
+		s, ok := spans[tc.text]
+		if !ok {
+			t.Errorf("no span for %q in %+v", tc.text, doc.Lines)
+			continue
+		}
+		if s.NodeKind != tc.kind || s.Link != tc.link || !tc.check(s.Style) {
+			t.Errorf("unexpected span for %q: %+v", tc.text, s)
+		}
+	}
+}
+
+// corpus returns the test documents.
+func corpus(t *testing.T) []string {
+	t.Helper()
+	files, err := filepath.Glob("testdata/*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := filepath.Glob("testdata/issues/*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(files, issues...)
+}
+
+// corpusOptions returns options enabling the optional extensions, for
+// rendering the test documents.
+func corpusOptions(style string) []TermRendererOption {
+	return []TermRendererOption{
+		WithStandardStyle(style),
+		WithWordWrap(60),
+		WithEmoji(),
+		WithMath(),
+		WithDiagrams(),
+		WithMark(),
+		WithSuperscript(),
+		WithSubscript(),
+		WithInserted(),
+		WithWikiLinks(),
+		WithAbbreviations(gansi.AbbreviationsGlossary),
+		WithFrontMatter(gansi.FrontMatterRender),
+		WithDetails(gansi.DetailsCollapsed),
+		WithTableOfContents(gansi.TOCTop, 3),
+		WithLinkFooters(gansi.LinkFootersSection),
+		WithBidi(gansi.BidiVisual),
+	}
+}
+
+// streamOptions returns the options of corpusOptions that keep the rendering
+// of a block independent of the blocks following it. Code is highlighted in
+// true color, since the closest 256 color to some isn't always the same.
+func streamOptions(style string) []TermRendererOption {
+	return []TermRendererOption{
+		WithStandardStyle(style),
+		WithChromaFormatter("terminal16m"),
+		WithWordWrap(60),
+		WithEmoji(),
+		WithMath(),
+		WithDiagrams(),
+		WithMark(),
+		WithSuperscript(),
+		WithSubscript(),
+		WithInserted(),
+		WithWikiLinks(),
+		WithFrontMatter(gansi.FrontMatterRender),
+		WithDetails(gansi.DetailsCollapsed),
+		WithBidi(gansi.BidiVisual),
+	}
+}
+
+// TestRenderDocumentCorpus checks that documents reproduce the rendered
+// output of every test document.
+func TestRenderDocumentCorpus(t *testing.T) {
+	for _, f := range corpus(t) {
+		t.Run(f, func(t *testing.T) {
+			in, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, style := range []string{styles.DarkStyle, styles.NoTTYStyle} {
+				r, err := NewTermRenderer(corpusOptions(style)...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				doc, err := r.RenderDocument(string(in))
+				if err != nil {
+					t.Fatal(err)
+				}
+				out, err := r.Render(string(in))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := doc.String(); got != out {
+					t.Fatalf("%s: document doesn't reproduce the rendered output:\n%q\n%q", style, got, out)
+				}
+				for i, line := range doc.Visible() {
+					if got, want := line.Text(), ansi.Strip(line.String()); got != want {
+						t.Errorf("%s: line %d: expected text %q, got %q", style, i, want, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIncrementalRenderer(t *testing.T) {
+	in, err := os.ReadFile("testdata/readme.markdown.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := []TermRendererOption{
+		WithStandardStyle(styles.DarkStyle),
+		WithWordWrap(60),
+	}
+
+	r, err := NewIncrementalRenderer(options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewTermRenderer(options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const chunk = 7
+	var prev []string
+	for i := 0; i < len(in); i += chunk {
+		end := min(i+chunk, len(in))
+		unchanged, err := r.Append(string(in[i:end]))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected, err := tr.Render(string(in[:end]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.String() != expected {
+			t.Fatalf("output after %d bytes differs from Render", end)
+		}
+
+		lines := r.Lines()
+		if unchanged > len(prev) || unchanged > len(lines) {
+			t.Fatalf("after %d bytes: %d unchanged lines out of %d", end, unchanged, len(lines))
+		}
+		for j := 0; j < unchanged; j++ {
+			if lines[j] != prev[j] {
+				t.Fatalf("after %d bytes: line %d reported unchanged but differs", end, j)
+			}
+		}
+		if unchanged < len(prev) && unchanged < len(lines) && lines[unchanged] == prev[unchanged] {
+			t.Errorf("after %d bytes: line %d reported changed but is the same", end, unchanged)
+		}
+		prev = lines
+	}
+
+	r.Reset()
+	if _, err := r.Append("# Hello"); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := tr.Render("# Hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.String() != expected {
+		t.Errorf("unexpected output after Reset: %q", r.String())
+	}
+}
+
+func TestIncrementalRendererShortcutReferences(t *testing.T) {
+	r, err := NewIncrementalRenderer(WithStandardStyle(styles.AsciiStyle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewTermRenderer(WithStandardStyle(styles.AsciiStyle))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var in string
+	for i, chunk := range []string{
+		"A [shortcut] link.\n\n",
+		"Some more text.\n\n",
+		"[shortcut]: https://charm.land\n",
+	} {
+		in += chunk
+		if _, err := r.Append(chunk); err != nil {
+			t.Fatal(err)
+		}
+		expected, err := tr.Render(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.String(); got != expected {
+			t.Fatalf("output after %d bytes differs from Render:\n%s\nexpected:\n%s", len(in), got, expected)
+		}
+		if i == 1 && r.committed == 0 {
+			t.Error("block with shortcut reference link didn't get cached")
+		}
+	}
+}
+
+// TestIncrementalRendererCorpus checks that appending every test document,
+// at once and in chunks, renders the same as Render.
+func TestIncrementalRendererCorpus(t *testing.T) {
+	for _, f := range corpus(t) {
+		t.Run(f, func(t *testing.T) {
+			in, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tr, err := NewTermRenderer(streamOptions(styles.DarkStyle)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, chunk := range []int{len(in), 64, 5} {
+				r, err := NewIncrementalRenderer(streamOptions(styles.DarkStyle)...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i := 0; i < len(in); i += chunk {
+					end := min(i+chunk, len(in))
+					if _, err := r.Append(string(in[i:end])); err != nil {
+						t.Fatal(err)
+					}
+					if chunk < 16 && end < len(in) {
+						continue
+					}
+					expected, err := tr.Render(string(in[:end]))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got := r.String(); got != expected {
+						t.Fatalf("chunks of %d: output after %d bytes differs from Render:\n%s\nexpected:\n%s", chunk, end, got, expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestTermRendererStreamingCorpus checks that streaming every test document,
+// at once and in chunks, renders the same as Render.
+func TestTermRendererStreamingCorpus(t *testing.T) {
+	for _, f := range corpus(t) {
+		t.Run(f, func(t *testing.T) {
+			in, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tr, err := NewTermRenderer(streamOptions(styles.DarkStyle)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := tr.Render(string(in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, chunk := range []int{len(in), 64, 5} {
+				var out bytes.Buffer
+				r, err := NewTermRenderer(append(streamOptions(styles.DarkStyle), WithStreaming(&out))...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i := 0; i < len(in); i += chunk {
+					if _, err := r.Write(in[i:min(i+chunk, len(in))]); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := r.Close(); err != nil {
+					t.Fatal(err)
+				}
+				if out.String() != expected {
+					t.Fatalf("chunks of %d: streamed output differs from Render:\n%s\nexpected:\n%s", chunk, out.String(), expected)
+				}
+			}
+		})
+	}
+}
+
+func TestWithAutolinkPatterns(t *testing.T) {
+	r, err := NewTermRenderer(
+		WithStandardStyle(styles.NoTTYStyle),
+		WithAutolinkPatterns(autolink.GitLab("")...),
+		WithAutolinkPatterns(autolink.Jira("")...),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := r.Render("See https://gitlab.com/group/proj/-/merge_requests/42, " +
+		"<https://acme.atlassian.net/browse/PROJ-123> and " +
+		"https://github.com/charmbracelet/glamour/issues/1.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = ansi.Strip(out)
+	for _, s := range []string{"group/proj!42", "PROJ-123", "charmbracelet/glamour#1"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in output:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "https://") {
+		t.Errorf("expected no URLs in output:\n%s", out)
+	}
+}
+
+func TestWithReferenceLinks(t *testing.T) {
+	r, err := NewTermRenderer(
+		WithStandardStyle(styles.DarkStyle),
+		WithReferenceLinks(RepoContext{Owner: "charmbracelet", Repo: "glamour"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := r.Render("Fixed in #123 by @meowgorithm, but not in `#456`.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []string{
+		"https://github.com/charmbracelet/glamour/issues/123",
+		"https://github.com/meowgorithm",
+	} {
+		if !strings.Contains(out, ";"+u+"\a") {
+			t.Errorf("expected a hyperlink to %s in output:\n%q", u, out)
+		}
+	}
+	if strings.Contains(out, "issues/456") {
+		t.Errorf("expected no link for the reference in code:\n%q", out)
+	}
+	if plain := ansi.Strip(out); !strings.Contains(plain, "Fixed in #123 by @meowgorithm") {
+		t.Errorf("unexpected text:\n%s", plain)
+	}
+}
+
+func TestWithMath(t *testing.T) {
+	in := "Pythagoras: $a^2 + b^2 = c^2$ for $5.\n"
+	for _, tc := range []struct {
+		name    string
+		options []TermRendererOption
+		want    string
+	}{
+		{"enabled", []TermRendererOption{WithMath()}, "a² + b² = c² for $5."},
+		{"disabled", nil, "$a^2 + b^2 = c^2$ for $5."},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewTermRenderer(append(tc.options, WithStandardStyle(styles.DarkStyle))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := r.Render(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plain := ansi.Strip(out); !strings.Contains(plain, tc.want) {
+				t.Errorf("expected %q in output:\n%s", tc.want, plain)
+			}
+		})
+	}
+}
+
+func TestRenderWithMeta(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		title string
+	}{
+		{"yaml", "---\ntitle: Hello\ntags: [a, b]\n---\n\nBody\n", "Hello"},
+		{"toml", "+++\ntitle = \"Hello\"\ntags = [\"a\", \"b\"]\n+++\n\nBody\n", "Hello"},
+		{"none", "Body\n", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewTermRenderer(
+				WithStandardStyle(styles.DarkStyle),
+				WithFrontMatter(gansi.FrontMatterStrip),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out, meta, err := r.RenderWithMeta(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if title, _ := meta["title"].(string); title != tc.title {
+				t.Errorf("expected title %q, got %q", tc.title, title)
+			}
+			if tc.title != "" && len(meta["tags"].([]any)) != 2 {
+				t.Errorf("expected two tags, got %v", meta["tags"])
+			}
+			if plain := ansi.Strip(out); strings.Contains(plain, "title") || !strings.Contains(plain, "Body") {
+				t.Errorf("expected the front matter to be stripped:\n%s", plain)
+			}
+		})
+	}
+}
+
+func TestWithFrontMatterThematicBreaks(t *testing.T) {
+	const in = "---\n\nIntro text.\n\n## Heading\n\nMore text.\n\n---\n\nFooter."
+	r, err := NewTermRenderer(
+		WithStandardStyle(styles.DarkStyle),
+		WithFrontMatter(gansi.FrontMatterStrip),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, meta, err := r.RenderWithMeta(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta != nil {
+		t.Errorf("expected no front matter, got %v", meta)
+	}
+	plain := ansi.Strip(out)
+	for _, s := range []string{"Intro text.", "Heading", "More text.", "Footer."} {
+		if !strings.Contains(plain, s) {
+			t.Errorf("expected %q to be rendered:\n%s", s, plain)
+		}
+	}
+
+	// without the option, the leading break doesn't keep blocks from being
+	// cached
+	ir, err := NewIncrementalRenderer(WithStandardStyle(styles.DarkStyle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ir.Append("---\n\nIntro text.\n\nMore text.\n\n"); err != nil {
+		t.Fatal(err)
+	}
+	if ir.committed == 0 {
+		t.Error("expected blocks after a leading thematic break to be cached")
+	}
+}
+
 func TestWithChromaFormatterDefault(t *testing.T) {
 	r, err := NewTermRenderer(
 		WithStandardStyle(styles.DarkStyle),
@@ -323,3 +1180,128 @@ func TestWithChromaFormatterCustom(t *testing.T) {
 
 	golden.RequireEqual(t, []byte(b))
 }
+
+func TestWithHTMLPolicy(t *testing.T) {
+	in := "Some <span>spanned</span> and <big>big</big> text.\n"
+	policy := bluemonday.NewPolicy()
+	policy.AllowElements("span")
+
+	for _, tc := range []struct {
+		name    string
+		options []TermRendererOption
+		want    string
+	}{
+		{"default", nil, "Some spanned and big text."},
+		{"custom", []TermRendererOption{WithHTMLPolicy(policy)}, "Some <span>spanned</span> and big text."},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewTermRenderer(append(tc.options, WithStandardStyle(styles.DarkStyle))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := r.Render(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plain := ansi.Strip(out); !strings.Contains(plain, tc.want) {
+				t.Errorf("expected %q in output:\n%s", tc.want, plain)
+			}
+		})
+	}
+}
+
+func TestWithMarks(t *testing.T) {
+	in := "==a== ^2^ ~2~ ++b++\n"
+	for _, tc := range []struct {
+		name    string
+		options []TermRendererOption
+		want    string
+	}{
+		{"none", nil, "==a== ^2^ 2 ++b++"},
+		{"mark", []TermRendererOption{WithMark()}, "a ^2^ 2 ++b++"},
+		{"superscript", []TermRendererOption{WithSuperscript()}, "==a== ² 2 ++b++"},
+		{"subscript", []TermRendererOption{WithSubscript()}, "==a== ^2^ ₂ ++b++"},
+		{"inserted", []TermRendererOption{WithInserted()}, "==a== ^2^ 2 b"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewTermRenderer(append(tc.options, WithStandardStyle(styles.DarkStyle))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := r.Render(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plain := ansi.Strip(out); !strings.Contains(plain, tc.want) {
+				t.Errorf("expected %q in output:\n%s", tc.want, plain)
+			}
+		})
+	}
+}
+
+func TestWithAbbreviations(t *testing.T) {
+	in := "A `CLI` CLI and a CLI.\n\n*[CLI]: command-line interface\n"
+	for _, tc := range []struct {
+		name    string
+		options []TermRendererOption
+		want    string
+	}{
+		{"none", nil, "*[CLI]: command-line interface"},
+		{"marked", []TermRendererOption{WithAbbreviations(gansi.AbbreviationsMarked)}, " CLI and a CLI."},
+		{"expanded", []TermRendererOption{WithAbbreviations(gansi.AbbreviationsExpanded)}, " CLI (command-line interface) and a CLI."},
+		{"glossary", []TermRendererOption{WithAbbreviations(gansi.AbbreviationsGlossary)}, "CLI  command-line interface"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewTermRenderer(append(tc.options, WithStandardStyle(styles.DarkStyle))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := r.Render(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plain := ansi.Strip(out); !strings.Contains(plain, tc.want) {
+				t.Errorf("expected %q in output:\n%s", tc.want, plain)
+			}
+		})
+	}
+}
+
+// titleResolver resolves wiki links to pages titled after their target.
+type titleResolver struct{}
+
+func (titleResolver) Resolve(dest string) (string, string, bool) {
+	return "/wiki/" + dest, "The " + dest + " page", dest != "missing"
+}
+
+func TestWithLinkResolver(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Page.md"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	in := "[[page]] [[page|alias]] [[missing]]\n"
+	for _, tc := range []struct {
+		name    string
+		options []TermRendererOption
+		want    string
+	}{
+		{"none", nil, "[[page]] [[page|alias]] [[missing]]"},
+		{"unresolved", []TermRendererOption{WithWikiLinks()}, "page /page alias /page missing /missing"},
+		{"files", []TermRendererOption{WithWikiLinks(), WithLinkResolver(gansi.FileResolver{Root: dir})}, "page /Page.md alias /Page.md missing /missing"},
+		{"titles", []TermRendererOption{WithWikiLinks(), WithLinkResolver(titleResolver{})}, "The page page /wiki/page alias /wiki/page The missing page /missing"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewTermRenderer(append(tc.options, WithStandardStyle(styles.DarkStyle))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := r.Render(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plain := ansi.Strip(out); !strings.Contains(plain, tc.want) {
+				t.Errorf("expected %q in output:\n%s", tc.want, plain)
+			}
+		})
+	}
+}
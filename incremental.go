@@ -0,0 +1,453 @@
+package glamour
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"charm.land/glamour/v2/ansi"
+	"charm.land/glamour/v2/internal/details"
+	"charm.land/glamour/v2/internal/noderange"
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// IncrementalRenderer renders markdown that arrives piece by piece, such as
+// streamed model responses. Top-level blocks that can no longer change are
+// rendered once and cached; only the trailing open block is re-rendered when
+// more text is appended.
+//
+// Blocks using full or collapsed reference links that haven't been defined
+// yet are kept open until the definition arrives; cached blocks using
+// shortcut reference links are rendered again once they get defined. Tables
+// of contents only list the headings that have arrived when a block gets
+// cached.
+type IncrementalRenderer struct {
+	tr *TermRenderer
+	// redraw is set if cached blocks may be rendered again, which isn't the
+	// case when they've already been streamed.
+	redraw bool
+
+	src []byte
+	// scanned is the offset up to which src has been scanned for block
+	// boundaries, splits are the boundaries found so far.
+	scanned   int
+	splits    []int
+	fence     string
+	prevBlank bool
+
+	// committed is the offset up to which blocks are cached; context is the
+	// offset of the last cached block with any output. Cached blocks are
+	// rendered along with it, so they're laid out as if they'd been rendered
+	// as part of the whole document.
+	committed, context int
+	hasContext         bool
+	// refs are the link reference definitions known when the blocks were
+	// cached.
+	refs []parser.Reference
+	// checkpoints are the states before each cache, shortcuts the index of
+	// the first one cached with each undefined shortcut reference link.
+	checkpoints []checkpoint
+	shortcuts   map[string]int
+
+	lines  []string
+	output []string
+}
+
+// checkpoint is the state of an IncrementalRenderer before caching blocks.
+type checkpoint struct {
+	committed, context int
+	hasContext         bool
+	lines              int
+	refs               []parser.Reference
+}
+
+// NewIncrementalRenderer returns a new IncrementalRenderer with the given
+// options.
+func NewIncrementalRenderer(options ...TermRendererOption) (*IncrementalRenderer, error) {
+	tr, err := NewTermRenderer(options...)
+	if err != nil {
+		return nil, err
+	}
+	r := &IncrementalRenderer{tr: tr, redraw: true}
+	r.Reset()
+	return r, nil
+}
+
+// Reset discards all text appended so far.
+func (r *IncrementalRenderer) Reset() {
+	*r = IncrementalRenderer{
+		tr:        r.tr,
+		redraw:    r.redraw,
+		prevBlank: true,
+	}
+}
+
+// Append appends markdown and renders it. It returns the number of leading
+// lines of the output that are unchanged since the previous call, so only
+// the lines after them need to be redrawn.
+func (r *IncrementalRenderer) Append(s string) (int, error) {
+	if err := r.write([]byte(s)); err != nil {
+		return 0, err
+	}
+
+	tail, err := r.render(r.context, r.committed, len(r.src), r.refs)
+	if err != nil {
+		return 0, err
+	}
+	output := append(append([]string{}, r.lines...), tail...)
+
+	unchanged := 0
+	for unchanged < len(output) && unchanged < len(r.output) && output[unchanged] == r.output[unchanged] {
+		unchanged++
+	}
+	r.output = output
+	return unchanged, nil
+}
+
+// Lines returns the rendered lines.
+func (r *IncrementalRenderer) Lines() []string {
+	return r.output
+}
+
+// String returns the rendered markdown.
+func (r *IncrementalRenderer) String() string {
+	return strings.Join(r.output, "\n")
+}
+
+// write appends markdown and caches the blocks it completes.
+func (r *IncrementalRenderer) write(b []byte) error {
+	r.src = append(r.src, b...)
+	r.scan()
+
+	doc, all := r.parse(len(r.src))
+	known := definedLabels(all)
+	if r.redraw && r.restore(known) {
+		doc, _ = r.parse(len(r.src))
+	}
+	splits := r.boundaries(doc)
+	refs := r.references(splits)
+	defined := definedLabels(refs)
+
+	// shortcut reference links are kept open as well once their definition
+	// has arrived, until it's complete
+	end := r.committed
+	for _, split := range splits {
+		labels, shortcuts := referenceLabels(r.src[end:split])
+		for _, label := range shortcuts {
+			if known[label] {
+				labels = append(labels, label)
+			}
+		}
+		if !allDefined(labels, defined) {
+			break
+		}
+		end = split
+	}
+	if end == r.committed {
+		return nil
+	}
+	return r.commit(end, refs)
+}
+
+// parse parses the text from the cached blocks up to end, and returns it
+// along with the link reference definitions known up to there.
+func (r *IncrementalRenderer) parse(end int) (ast.Node, []parser.Reference) {
+	pc := parser.NewContext()
+	for _, ref := range r.refs {
+		pc.AddReference(ref)
+	}
+	src := r.src[r.committed:end]
+	doc := r.tr.md.Parser().Parse(text.NewReader(src), parser.WithContext(pc))
+	if r.tr.ansiOptions.Details != ansi.DetailsNone {
+		details.Transform(doc, src)
+	}
+	return doc, pc.References()
+}
+
+// references returns the link reference definitions of the blocks before the
+// last of the given boundaries. Definitions after it may still change.
+func (r *IncrementalRenderer) references(splits []int) []parser.Reference {
+	if len(splits) == 0 {
+		return r.refs
+	}
+	_, refs := r.parse(splits[len(splits)-1])
+	return refs
+}
+
+// definedLabels returns the set of labels of link reference definitions.
+func definedLabels(refs []parser.Reference) map[string]bool {
+	defined := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		defined[util.ToLinkReference(ref.Label())] = true
+	}
+	return defined
+}
+
+// boundaries returns the block boundaries found after the cached blocks that
+// the blocks of doc, the parsed text that hasn't been cached, don't extend
+// across, and that later text can't join either.
+//
+// Blocks without any source position, like thematic breaks, are only known
+// to be between the blocks around them, so there mustn't be any of them next
+// to a boundary.
+func (r *IncrementalRenderer) boundaries(doc ast.Node) []int {
+	type block struct {
+		start, end int
+		kind       ast.NodeKind
+	}
+	var blocks []block
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		start, end := noderange.Of(n, r.src[r.committed:])
+		blocks = append(blocks, block{r.committed + start, r.committed + end, n.Kind()})
+	}
+	empty := func(b block) bool {
+		return b.start == b.end
+	}
+
+	var splits []int
+next:
+	for _, split := range r.splits {
+		if split <= r.committed {
+			continue
+		}
+		// blocks before the boundary, up to the first one after it
+		i, prev := 0, -1
+		for ; i < len(blocks); i++ {
+			b := blocks[i]
+			switch {
+			case empty(b):
+				continue
+			case b.start < split && b.end > split:
+				continue next
+			case b.start >= split:
+			default:
+				prev = i
+				continue
+			}
+			break
+		}
+		for j := prev + 1; j < i; j++ {
+			if empty(blocks[j]) {
+				continue next
+			}
+		}
+		// the last block may still become a term of the definition list
+		// before it
+		if prev >= 0 && blocks[prev].kind == extast.KindDefinitionList && i >= len(blocks)-1 {
+			continue
+		}
+		splits = append(splits, split)
+	}
+	return splits
+}
+
+// restore goes back to the state before the first cached blocks using
+// shortcut reference links that have since been defined, and reports whether
+// it did.
+func (r *IncrementalRenderer) restore(defined map[string]bool) bool {
+	i := len(r.checkpoints)
+	for label, c := range r.shortcuts {
+		if defined[label] {
+			i = min(i, c)
+		}
+	}
+	if i == len(r.checkpoints) {
+		return false
+	}
+
+	c := r.checkpoints[i]
+	r.committed, r.context, r.hasContext = c.committed, c.context, c.hasContext
+	r.lines = r.lines[:c.lines]
+	r.refs = c.refs
+	r.checkpoints = r.checkpoints[:i]
+	for label, c := range r.shortcuts {
+		if c >= i {
+			delete(r.shortcuts, label)
+		}
+	}
+	return true
+}
+
+// commit caches the blocks up to the given offset.
+func (r *IncrementalRenderer) commit(end int, refs []parser.Reference) error {
+	lines, err := r.render(r.context, r.committed, end, refs)
+	if err != nil {
+		return err
+	}
+
+	defined := definedLabels(refs)
+	_, shortcuts := referenceLabels(r.src[r.committed:end])
+	for _, label := range shortcuts {
+		if _, ok := r.shortcuts[label]; !ok && !defined[label] {
+			if r.shortcuts == nil {
+				r.shortcuts = make(map[string]int)
+			}
+			r.shortcuts[label] = len(r.checkpoints)
+		}
+	}
+	r.checkpoints = append(r.checkpoints, checkpoint{
+		committed:  r.committed,
+		context:    r.context,
+		hasContext: r.hasContext,
+		lines:      len(r.lines),
+		refs:       r.refs,
+	})
+
+	if len(lines) > 0 {
+		r.lines = append(r.lines, lines...)
+		r.context = r.committed
+		r.hasContext = true
+	}
+	r.committed = end
+	r.refs = refs
+	return nil
+}
+
+// render renders src[context:end] and returns the lines following the
+// blocks before from. Unless the output ends with the whole document, only
+// lines up to the last line of the blocks are returned. refs are link
+// reference definitions defined outside of the rendered text.
+func (r *IncrementalRenderer) render(context, from, end int, refs []parser.Reference) ([]string, error) {
+	pc := parser.NewContext()
+	for _, ref := range refs {
+		pc.AddReference(ref)
+	}
+	out, sm, err := r.tr.renderWithSourceMap(r.src[context:end], parser.WithContext(pc))
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(out), "\n")
+
+	// blocks before from end before the blank line preceding it
+	cut, last := -1, -1
+	for _, span := range sm.Spans {
+		if span.Depth > 0 {
+			continue
+		}
+		if span.End < from-context {
+			cut = max(cut, span.EndLine)
+		} else {
+			last = max(last, span.EndLine)
+		}
+	}
+	if !r.hasContext {
+		cut = -1
+	}
+
+	if end < len(r.src) {
+		if last <= cut {
+			return nil, nil
+		}
+		return lines[cut+1 : last+1], nil
+	}
+	return lines[cut+1:], nil
+}
+
+const (
+	frontMatterYAML = "---"
+	frontMatterTOML = "+++"
+	mathFence       = "$$"
+)
+
+var (
+	fenceLine     = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	mathLine      = regexp.MustCompile(`^ {0,3}\$\$`)
+	continuedLine = regexp.MustCompile(`^([ \t]|[-+*:][ \t]|[-+*:]$|\d{1,9}[.)]([ \t]|$))`)
+	referenceLink = regexp.MustCompile(`(^|[^\\])\[([^\[\]]*)\](\[([^\[\]]*)\])?([(:]?)`)
+)
+
+// referenceLabels returns the labels of the full and collapsed reference
+// links the markdown might contain, followed by those of shortcut reference
+// links. Task list markers are left out.
+func referenceLabels(b []byte) ([]string, []string) {
+	var labels, shortcuts []string
+	for _, m := range referenceLink.FindAllSubmatch(b, -1) {
+		// inline links and definitions
+		if len(m[5]) > 0 && len(m[3]) == 0 {
+			continue
+		}
+		label := m[2]
+		if len(m[4]) > 0 {
+			label = m[4]
+		}
+		switch strings.TrimSpace(string(label)) {
+		case "", "x", "X":
+			continue
+		}
+		if len(m[3]) == 0 {
+			shortcuts = append(shortcuts, util.ToLinkReference(label))
+			continue
+		}
+		labels = append(labels, util.ToLinkReference(label))
+	}
+	return labels, shortcuts
+}
+
+func allDefined(labels []string, defined map[string]bool) bool {
+	for _, l := range labels {
+		if !defined[l] {
+			return false
+		}
+	}
+	return true
+}
+
+// scan looks for the last position in the appended text where a new
+// top-level block starts that can't affect the blocks before it: a line
+// after a blank line that isn't indented, fenced or a list item.
+func (r *IncrementalRenderer) scan() {
+	for {
+		i := bytes.IndexByte(r.src[r.scanned:], '\n')
+		if i < 0 {
+			return
+		}
+		start := r.scanned
+		line := string(r.src[start : start+i])
+		r.scanned = start + i + 1
+
+		// front matter may contain blank lines, so it's kept in one block
+		delim := strings.TrimRightFunc(line, unicode.IsSpace)
+		switch {
+		case start == 0 && r.tr.frontMatter && (delim == frontMatterYAML || delim == frontMatterTOML):
+			r.fence = delim
+			r.prevBlank = false
+			continue
+		case r.fence == frontMatterYAML || r.fence == frontMatterTOML:
+			if delim == r.fence {
+				r.fence = ""
+			}
+			continue
+		}
+
+		blank := strings.TrimSpace(line) == ""
+		if r.fence == "" && r.prevBlank && !blank && !continuedLine.MatchString(line) {
+			r.splits = append(r.splits, start)
+		}
+
+		// so may display math
+		math := strings.TrimSpace(line)
+		switch {
+		case r.fence == "" && mathLine.MatchString(line) &&
+			(len(math) == len(mathFence) || !strings.HasSuffix(math, mathFence)):
+			r.fence = mathFence
+		case r.fence == mathFence && strings.HasSuffix(math, mathFence):
+			r.fence = ""
+		}
+
+		if m := fenceLine.FindStringSubmatch(line); m != nil {
+			switch {
+			case r.fence == "":
+				r.fence = m[1]
+			case m[1][0] == r.fence[0] && len(m[1]) >= len(r.fence) &&
+				strings.TrimSpace(line[len(m[0]):]) == "":
+				r.fence = ""
+			}
+		}
+		r.prevBlank = blank && r.fence == ""
+	}
+}
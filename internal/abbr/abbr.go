@@ -0,0 +1,217 @@
+// Package abbr provides a goldmark extension that parses abbreviation
+// definitions like "*[HTML]: Hyper Text Markup Language" and marks the
+// abbreviations they define in the text of a document.
+package abbr
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindAbbreviation is the kind of abbreviation nodes.
+var KindAbbreviation = ast.NewNodeKind("Abbreviation")
+
+// An Abbreviation node holds an occurrence of a defined term.
+type Abbreviation struct {
+	ast.BaseInline
+
+	Term      string
+	Expansion string
+}
+
+// Kind implements ast.Node.Kind.
+func (n *Abbreviation) Kind() ast.NodeKind {
+	return KindAbbreviation
+}
+
+// Dump implements ast.Node.Dump.
+func (n *Abbreviation) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Term":      n.Term,
+		"Expansion": n.Expansion,
+	}, nil)
+}
+
+var kindDefinition = ast.NewNodeKind("AbbreviationDefinition")
+
+// definition is an abbreviation definition. Definitions are removed from
+// the document once the abbreviations they define have been marked.
+type definition struct {
+	ast.BaseBlock
+
+	term      string
+	expansion string
+}
+
+// Kind implements ast.Node.Kind.
+func (n *definition) Kind() ast.NodeKind {
+	return kindDefinition
+}
+
+// Dump implements ast.Node.Dump.
+func (n *definition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Term": n.term,
+	}, nil)
+}
+
+var definitionPattern = regexp.MustCompile(`^ {0,3}\*\[([^\]]+)\]:[ \t]*(.*?)\s*$`)
+
+type definitionParser struct{}
+
+// Trigger implements parser.BlockParser.Trigger.
+func (p *definitionParser) Trigger() []byte {
+	return []byte{'*'}
+}
+
+// Open implements parser.BlockParser.Open.
+func (p *definitionParser) Open(_ ast.Node, reader text.Reader, _ parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	m := definitionPattern.FindSubmatch(line)
+	if m == nil {
+		return nil, parser.NoChildren
+	}
+	reader.Advance(segment.Len() - 1)
+	return &definition{term: string(m[1]), expansion: string(m[2])}, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser.Continue.
+func (p *definitionParser) Continue(ast.Node, text.Reader, parser.Context) parser.State {
+	return parser.Close
+}
+
+// Close implements parser.BlockParser.Close.
+func (p *definitionParser) Close(ast.Node, text.Reader, parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph.
+func (p *definitionParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine.
+func (p *definitionParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+type transformer struct{}
+
+// Transform implements parser.ASTTransformer.Transform.
+func (t *transformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	var defs []*definition
+	var texts []*ast.Text
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *definition:
+			defs = append(defs, n)
+		case *ast.CodeSpan, *ast.Link, *ast.AutoLink, *ast.Image:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			texts = append(texts, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	if len(defs) == 0 {
+		return
+	}
+
+	// the first definition of a term wins, like with link references
+	expansions := map[string]string{}
+	var terms []string
+	for _, d := range defs {
+		if _, ok := expansions[d.term]; !ok {
+			expansions[d.term] = d.expansion
+			terms = append(terms, d.term)
+		}
+		d.Parent().RemoveChild(d.Parent(), d)
+	}
+	// longer terms are matched first, so "HTML5" beats "HTML"
+	slices.SortStableFunc(terms, func(a, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+
+	for _, n := range texts {
+		mark(n, reader.Source(), terms, expansions)
+	}
+}
+
+// mark splits a text node around the terms it holds, which get wrapped in
+// abbreviation nodes. The node itself keeps the text following the last
+// term, along with its line break.
+func mark(n *ast.Text, source []byte, terms []string, expansions map[string]string) {
+	parent := n.Parent()
+	seg := n.Segment
+	start := seg.Start
+	for i := seg.Start; i < seg.Stop; {
+		term := match(source, i, seg.Stop, terms)
+		if term == "" {
+			_, size := utf8.DecodeRune(source[i:seg.Stop])
+			i += size
+			continue
+		}
+		if i > start {
+			parent.InsertBefore(parent, n, ast.NewTextSegment(text.NewSegment(start, i)))
+		}
+		a := &Abbreviation{Term: term, Expansion: expansions[term]}
+		a.AppendChild(a, ast.NewTextSegment(text.NewSegment(i, i+len(term))))
+		parent.InsertBefore(parent, n, a)
+		i += len(term)
+		start = i
+	}
+	n.Segment = seg.WithStart(start)
+}
+
+// match returns the term starting at source[i], or "". Terms have to start
+// and end at word boundaries.
+func match(source []byte, i, stop int, terms []string) string {
+	prev, _ := utf8.DecodeLastRune(source[:i])
+	for _, term := range terms {
+		end := i + len(term)
+		if end > stop || string(source[i:end]) != term {
+			continue
+		}
+		first, _ := utf8.DecodeRuneInString(term)
+		last, _ := utf8.DecodeLastRuneInString(term)
+		next, _ := utf8.DecodeRune(source[end:])
+		if isWord(first) && isWord(prev) || isWord(last) && isWord(next) {
+			continue
+		}
+		return term
+	}
+	return ""
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type extender struct{}
+
+// New returns an extension that parses abbreviations.
+func New() goldmark.Extender {
+	return &extender{}
+}
+
+// Extend implements goldmark.Extender.Extend.
+func (e *extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			// before thematic breaks and lists, which start with "*" too
+			util.Prioritized(&definitionParser{}, 100),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(&transformer{}, 100),
+		),
+	)
+}
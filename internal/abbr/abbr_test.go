@@ -0,0 +1,76 @@
+package abbr_test
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/glamour/v2/internal/abbr"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"word", "HTML is fun", "[HTML] is fun"},
+		{"every occurrence", "HTML and HTML", "[HTML] and [HTML]"},
+		{"word boundaries", "HTMLX XHTML HTML_ HTML.", "HTMLX XHTML HTML_ [HTML]."},
+		{"longest first", "HTML5 and HTML", "[HTML5] and [HTML]"},
+		{"punctuation", "C++ and C++11", "[C++] and [C++]11"},
+		{"code", "`HTML` and HTML", "HTML and [HTML]"},
+		{"link", "[HTML](https://example.com) HTML", "HTML [HTML]"},
+		{"emphasis", "*HTML*", "[HTML]"},
+		{"case", "html", "html"},
+		{"line break", "HTML\nHTML", "[HTML]\n[HTML]"},
+	}
+	defs := "\n\n*[HTML]: Hyper Text Markup Language\n*[HTML5]: HTML version 5\n*[C++]: C plus plus\n"
+	md := goldmark.New(goldmark.WithExtensions(abbr.New()))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			source := []byte(tc.in + defs)
+			doc := md.Parser().Parse(text.NewReader(source))
+			if n := doc.ChildCount(); n != 1 {
+				t.Fatalf("expected definitions to be removed, got %d blocks", n)
+			}
+			if got := dump(doc.FirstChild(), source); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	source := []byte("The W3C.\n*[W3C]: World Wide Web Consortium\n*[W3C]: ignored")
+	md := goldmark.New(goldmark.WithExtensions(abbr.New()))
+	doc := md.Parser().Parse(text.NewReader(source))
+
+	a, ok := doc.FirstChild().LastChild().PreviousSibling().(*abbr.Abbreviation)
+	if !ok {
+		t.Fatalf("expected an abbreviation, got %s", dump(doc.FirstChild(), source))
+	}
+	if a.Term != "W3C" || a.Expansion != "World Wide Web Consortium" {
+		t.Errorf("unexpected abbreviation %q: %q", a.Term, a.Expansion)
+	}
+}
+
+// dump returns the text of a node's children, with abbreviations enclosed
+// in brackets.
+func dump(n ast.Node, source []byte) string {
+	var b strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *abbr.Abbreviation:
+			b.WriteString("[" + dump(c, source) + "]")
+		case *ast.Text:
+			b.Write(c.Segment.Value(source))
+			if c.SoftLineBreak() {
+				b.WriteString("\n")
+			}
+		default:
+			b.WriteString(dump(c, source))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,210 @@
+// Package details turns <details> HTML blocks, which goldmark parses as
+// separate HTML blocks around the markdown they enclose, into nodes holding
+// their summary and body.
+package details
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"golang.org/x/net/html"
+)
+
+// KindDetails is the kind of details nodes.
+var KindDetails = ast.NewNodeKind("Details")
+
+// A Node is a <details> block. Its children are the blocks of its body, its
+// lines those holding its tags.
+type Node struct {
+	ast.BaseBlock
+
+	// Summary is the text of the <summary> tag.
+	Summary string
+	// Open is set if the block has the open attribute.
+	Open bool
+}
+
+// Kind implements ast.Node.Kind.
+func (n *Node) Kind() ast.NodeKind {
+	return KindDetails
+}
+
+// Dump implements ast.Node.Dump.
+func (n *Node) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Summary": n.Summary,
+	}, nil)
+}
+
+// part is a piece of an HTML block: the start or end of a details block, its
+// summary, or a line of other HTML.
+type part struct {
+	kind    partKind
+	summary string
+	open    bool
+	line    text.Segment
+}
+
+type partKind int
+
+const (
+	partStart partKind = iota
+	partEnd
+	partSummary
+	partLine
+)
+
+// Transform replaces the details blocks in a tree with nodes. Blocks that
+// aren't closed extend to the end of their parent.
+func Transform(root ast.Node, source []byte) {
+	var parents []ast.Node
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Kind() != ast.KindHTMLBlock {
+			return ast.WalkContinue, nil
+		}
+		if p := n.Parent(); hasDetails(n.(*ast.HTMLBlock), source) &&
+			(len(parents) == 0 || parents[len(parents)-1] != p) {
+			parents = append(parents, p)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, p := range parents {
+		transformChildren(p, source)
+	}
+}
+
+func transformChildren(parent ast.Node, source []byte) {
+	var children []ast.Node
+	for c := parent.FirstChild(); c != nil; c = c.NextSibling() {
+		children = append(children, c)
+	}
+	parent.RemoveChildren(parent)
+
+	stack := []ast.Node{parent}
+	target := func() ast.Node {
+		return stack[len(stack)-1]
+	}
+	for _, c := range children {
+		b, ok := c.(*ast.HTMLBlock)
+		if !ok || !hasDetails(b, source) {
+			target().AppendChild(target(), c)
+			continue
+		}
+
+		var lines *ast.HTMLBlock
+		for _, p := range split(b, source) {
+			if p.kind != partLine {
+				lines = nil
+			}
+			switch p.kind {
+			case partStart:
+				n := &Node{Open: p.open}
+				addLine(n, p.line)
+				target().AppendChild(target(), n)
+				stack = append(stack, n)
+			case partEnd:
+				if len(stack) > 1 {
+					addLine(target(), p.line)
+					stack = stack[:len(stack)-1]
+				}
+			case partSummary:
+				if n, ok := target().(*Node); ok && n.Summary == "" && !n.HasChildren() {
+					n.Summary = p.summary
+					addLine(n, p.line)
+				}
+			case partLine:
+				if lines == nil {
+					lines = ast.NewHTMLBlock(b.HTMLBlockType)
+					target().AppendChild(target(), lines)
+				}
+				lines.Lines().Append(p.line)
+			}
+		}
+	}
+}
+
+// addLine adds a line to the lines of a node, unless it's been added last.
+func addLine(n ast.Node, seg text.Segment) {
+	lines := n.Lines()
+	if lines.Len() > 0 && lines.At(lines.Len()-1) == seg {
+		return
+	}
+	lines.Append(seg)
+}
+
+// hasDetails reports whether an HTML block holds a details tag.
+func hasDetails(b *ast.HTMLBlock, source []byte) bool {
+	z := html.NewTokenizer(strings.NewReader(string(b.Lines().Value(source))))
+	for tt := z.Next(); tt != html.ErrorToken; tt = z.Next() {
+		if name, _ := z.TagName(); string(name) == "details" {
+			return true
+		}
+	}
+	return false
+}
+
+// split breaks an HTML block down into parts. Lines holding anything besides
+// details and summary tags are kept as they are.
+func split(b *ast.HTMLBlock, source []byte) []part {
+	var parts []part
+	var summary strings.Builder
+	inSummary := false
+	for i := 0; i < b.Lines().Len(); i++ {
+		seg := b.Lines().At(i)
+		var tags []part
+		other := false
+
+		z := html.NewTokenizer(strings.NewReader(string(seg.Value(source))))
+		for tt := z.Next(); tt != html.ErrorToken; tt = z.Next() {
+			name, _ := z.TagName()
+			switch {
+			case tt == html.TextToken && inSummary:
+				summary.Write(z.Text())
+			case tt == html.TextToken:
+				other = other || strings.TrimSpace(string(z.Text())) != ""
+			case string(name) == "details" && tt == html.StartTagToken:
+				tags = append(tags, part{kind: partStart, open: hasAttr(z, "open"), line: seg})
+			case string(name) == "details" && tt == html.EndTagToken:
+				tags = append(tags, part{kind: partEnd, line: seg})
+			case string(name) == "summary" && tt == html.StartTagToken:
+				inSummary = true
+				summary.Reset()
+			case string(name) == "summary" && tt == html.EndTagToken:
+				inSummary = false
+				tags = append(tags, part{
+					kind:    partSummary,
+					summary: strings.Join(strings.Fields(summary.String()), " "),
+					line:    seg,
+				})
+			case !inSummary:
+				other = true
+			}
+		}
+
+		// text following a closing tag belongs to the enclosing block
+		line := part{kind: partLine, line: seg}
+		switch {
+		case !other:
+			parts = append(parts, tags...)
+		case len(tags) > 0 && tags[0].kind == partEnd:
+			parts = append(append(parts, tags...), line)
+		default:
+			parts = append(append(parts, line), tags...)
+		}
+	}
+	return parts
+}
+
+// hasAttr reports whether the current tag of a tokenizer has an attribute.
+func hasAttr(z *html.Tokenizer, name string) bool {
+	for {
+		k, _, more := z.TagAttr()
+		if string(k) == name {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
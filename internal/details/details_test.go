@@ -0,0 +1,83 @@
+package details_test
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/glamour/v2/internal/details"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{
+			"blocks",
+			"<details>\n<summary>Title</summary>\n\nBody\n\n</details>\n\nAfter\n",
+			"Details(Title)[Paragraph] Paragraph",
+		},
+		{
+			"single html block",
+			"<details open><summary>Title</summary>\nBody\n</details>\n",
+			"Details(Title, open)[HTMLBlock]",
+		},
+		{
+			"multiline summary",
+			"<details>\n<summary>\n  A <b>long</b>\n  title\n</summary>\n\nBody\n\n</details>\n",
+			"Details(A long title)[Paragraph]",
+		},
+		{
+			"nested",
+			"<details>\n<summary>Outer</summary>\n\n<details>\n<summary>Inner</summary>\n\nBody\n\n</details>\n</details>\n",
+			"Details(Outer)[Details(Inner)[Paragraph]]",
+		},
+		{
+			"siblings",
+			"<details><summary>One</summary>\n\nBody\n\n</details>\n<details><summary>Two</summary>\n\nBody\n\n</details>\n",
+			"Details(One)[Paragraph] Details(Two)[Paragraph]",
+		},
+		{
+			"unclosed",
+			"<details>\n<summary>Title</summary>\n\nBody\n\n- item\n",
+			"Details(Title)[Paragraph List[ListItem[TextBlock]]]",
+		},
+		{
+			"in list",
+			"- <details><summary>Title</summary>\n\n  Body\n\n  </details>\n",
+			"List[ListItem[Details(Title)[Paragraph]]]",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			source := []byte(tc.in)
+			doc := goldmark.New().Parser().Parse(text.NewReader(source))
+			details.Transform(doc, source)
+			if got := dump(doc); got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+// dump describes the children of a node.
+func dump(n ast.Node) string {
+	var parts []string
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		s := c.Kind().String()
+		if d, ok := c.(*details.Node); ok {
+			s = "Details(" + d.Summary
+			if d.Open {
+				s += ", open"
+			}
+			s += ")"
+		}
+		if c.HasChildren() && c.FirstChild().Type() == ast.TypeBlock {
+			s += "[" + dump(c) + "]"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
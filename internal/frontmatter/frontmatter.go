@@ -0,0 +1,207 @@
+// Package frontmatter provides a goldmark extension that parses YAML and TOML
+// front matter at the start of a document.
+package frontmatter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the format of front matter.
+type Format int
+
+const (
+	// YAML front matter is delimited by "---" lines.
+	YAML Format = iota
+	// TOML front matter is delimited by "+++" lines.
+	TOML
+)
+
+// KindFrontMatter is the kind of front matter nodes.
+var KindFrontMatter = ast.NewNodeKind("FrontMatter")
+
+// A Node holds the front matter of a document.
+type Node struct {
+	ast.BaseBlock
+
+	Format Format
+	// Data holds the parsed fields, Keys their names in the order they
+	// appear in.
+	Data map[string]any
+	Keys []string
+}
+
+// Kind implements ast.Node.Kind.
+func (n *Node) Kind() ast.NodeKind {
+	return KindFrontMatter
+}
+
+// IsRaw implements ast.Node.IsRaw.
+func (n *Node) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.Dump.
+func (n *Node) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Keys": fmt.Sprint(n.Keys),
+	}, nil)
+}
+
+// delimiter returns the format front matter starting or ending with line is
+// in.
+func delimiter(line []byte) (Format, bool) {
+	switch string(util.TrimRightSpace(line)) {
+	case "---":
+		return YAML, true
+	case "+++":
+		return TOML, true
+	}
+	return 0, false
+}
+
+type frontMatterParser struct{}
+
+// Trigger implements parser.BlockParser.Trigger.
+func (p *frontMatterParser) Trigger() []byte {
+	return []byte{'-', '+'}
+}
+
+// Open implements parser.BlockParser.Open.
+func (p *frontMatterParser) Open(parent ast.Node, reader text.Reader, _ parser.Context) (ast.Node, parser.State) {
+	// front matter has to be the very first thing in a document
+	if l, _ := reader.Position(); l != 0 || parent.Type() != ast.TypeDocument {
+		return nil, parser.NoChildren
+	}
+	line, _ := reader.PeekLine()
+	format, ok := delimiter(line)
+	if !ok {
+		return nil, parser.NoChildren
+	}
+	n := &Node{Format: format}
+	if !parse(n, reader) {
+		return nil, parser.NoChildren
+	}
+	return n, parser.NoChildren
+}
+
+// parse parses the front matter starting at the current line, and reports
+// whether it's been closed and holds fields. If not, the line is a thematic
+// break rather than front matter.
+func parse(n *Node, reader text.Reader) bool {
+	l, pos := reader.Position()
+	defer reader.SetPosition(l, pos)
+
+	var buf bytes.Buffer
+	reader.AdvanceLine()
+	for {
+		line, _ := reader.PeekLine()
+		if line == nil {
+			return false
+		}
+		if f, ok := delimiter(line); ok && f == n.Format {
+			break
+		}
+		buf.Write(line)
+		reader.AdvanceLine()
+	}
+
+	var err error
+	switch n.Format {
+	case YAML:
+		n.Data, n.Keys, err = parseYAML(buf.Bytes())
+	case TOML:
+		n.Data, n.Keys, err = parseTOML(buf.Bytes())
+	}
+	return err == nil && len(n.Keys) > 0
+}
+
+// Continue implements parser.BlockParser.Continue.
+func (p *frontMatterParser) Continue(node ast.Node, reader text.Reader, _ parser.Context) parser.State {
+	n := node.(*Node)
+	line, segment := reader.PeekLine()
+	if format, ok := delimiter(line); ok && format == n.Format {
+		reader.Advance(segment.Len())
+		return parser.Close
+	}
+	n.Lines().Append(segment)
+	return parser.Continue | parser.NoChildren
+}
+
+// Close implements parser.BlockParser.Close.
+func (p *frontMatterParser) Close(ast.Node, text.Reader, parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph.
+func (p *frontMatterParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine.
+func (p *frontMatterParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+func parseYAML(b []byte) (map[string]any, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, nil, fmt.Errorf("invalid YAML front matter: %w", err)
+	}
+	if len(doc.Content) > 0 && doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, errors.New("invalid YAML front matter: not a mapping")
+	}
+	data := map[string]any{}
+	if err := doc.Decode(&data); err != nil {
+		return nil, nil, fmt.Errorf("invalid YAML front matter: %w", err)
+	}
+
+	var keys []string
+	if len(doc.Content) > 0 {
+		m := doc.Content[0].Content
+		for i := 0; i+1 < len(m); i += 2 {
+			keys = append(keys, m[i].Value)
+		}
+	}
+	return data, keys, nil
+}
+
+func parseTOML(b []byte) (map[string]any, []string, error) {
+	data := map[string]any{}
+	md, err := toml.Decode(string(b), &data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid TOML front matter: %w", err)
+	}
+
+	var keys []string
+	for _, k := range md.Keys() {
+		if len(k) == 1 {
+			keys = append(keys, k[0])
+		}
+	}
+	return data, keys, nil
+}
+
+type extender struct{}
+
+// New returns an extension that parses front matter.
+func New() goldmark.Extender {
+	return &extender{}
+}
+
+// Extend implements goldmark.Extender.Extend.
+func (e *extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			// before thematic breaks, which look the same
+			util.Prioritized(&frontMatterParser{}, 0),
+		),
+	)
+}
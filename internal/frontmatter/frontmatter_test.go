@@ -0,0 +1,63 @@
+package frontmatter_test
+
+import (
+	"slices"
+	"testing"
+
+	"charm.land/glamour/v2/internal/frontmatter"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestFrontMatter(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		format frontmatter.Format
+		keys   []string
+		found  bool
+	}{
+		{"yaml", "---\ntitle: Hi\ntags: [a, b]\nauthor:\n  name: x\n---\n\nBody", frontmatter.YAML, []string{"title", "tags", "author"}, true},
+		{"toml", "+++\ntitle = \"Hi\"\n\n[author]\nname = \"x\"\n+++\nBody", frontmatter.TOML, []string{"title", "author"}, true},
+		{"empty", "---\n---\nBody", 0, nil, false},
+		{"empty toml", "+++\n+++\nBody", 0, nil, false},
+		{"invalid", "---\ntitle: [\n---\n", 0, nil, false},
+		{"not a mapping", "---\n\nIntro.\n\n## Heading\n\n---\n\nFooter.", 0, nil, false},
+		{"invalid toml", "+++\n\nIntro.\n\n+++\n", 0, nil, false},
+
+		{"unclosed", "---\ntitle: Hi\n", 0, nil, false},
+		{"mixed delimiters", "---\ntitle: Hi\n+++\n", 0, nil, false},
+		{"not first", "Body\n\n---\ntitle: Hi\n---\n", 0, nil, false},
+	}
+	md := goldmark.New(goldmark.WithExtensions(frontmatter.New()))
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := md.Parser().Parse(text.NewReader([]byte(tc.in)))
+
+			var fm *frontmatter.Node
+			_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+				if n, ok := n.(*frontmatter.Node); ok && entering {
+					fm = n
+				}
+				return ast.WalkContinue, nil
+			})
+			if (fm != nil) != tc.found {
+				t.Fatalf("expected front matter: %t, got %t", tc.found, fm != nil)
+			}
+			if fm == nil {
+				return
+			}
+			if fm != doc.FirstChild() {
+				t.Error("expected front matter to be the first node")
+			}
+			if fm.Format != tc.format {
+				t.Errorf("expected format %d, got %d", tc.format, fm.Format)
+			}
+			if !slices.Equal(fm.Keys, tc.keys) {
+				t.Errorf("expected keys %v, got %v", tc.keys, fm.Keys)
+			}
+		})
+	}
+}
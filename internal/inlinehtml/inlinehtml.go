@@ -0,0 +1,174 @@
+// Package inlinehtml pairs the opening and closing tags of inline HTML,
+// which goldmark parses as separate raw HTML nodes, so the elements they
+// enclose can be rendered.
+package inlinehtml
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"golang.org/x/net/html"
+)
+
+// KindSpan is the kind of span nodes.
+var KindSpan = ast.NewNodeKind("HTMLSpan")
+
+// A Span node is an inline HTML element holding the nodes between its tags.
+type Span struct {
+	ast.BaseInline
+
+	Tag string
+}
+
+// Kind implements ast.Node.Kind.
+func (n *Span) Kind() ast.NodeKind {
+	return KindSpan
+}
+
+// Dump implements ast.Node.Dump.
+func (n *Span) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Tag": n.Tag,
+	}, nil)
+}
+
+// A Tag is an HTML tag.
+type Tag struct {
+	// Name is the lowercase name of the tag.
+	Name    string
+	Attrs   map[string]string
+	Closing bool
+	// Void is set for self-closing tags and elements that can't have
+	// content, like <br> and <img>.
+	Void bool
+}
+
+var voidElements = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+	"wbr": true,
+}
+
+// ParseTag parses raw HTML holding a single tag.
+func ParseTag(raw string) (Tag, bool) {
+	z := html.NewTokenizer(strings.NewReader(raw))
+	tt := z.Next()
+	switch tt {
+	case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+	default:
+		return Tag{}, false
+	}
+
+	name, more := z.TagName()
+	t := Tag{
+		Name:    string(name),
+		Attrs:   map[string]string{},
+		Closing: tt == html.EndTagToken,
+		Void:    tt == html.SelfClosingTagToken || voidElements[string(name)],
+	}
+	for more {
+		var k, v []byte
+		k, v, more = z.TagAttr()
+		t.Attrs[string(k)] = string(v)
+	}
+	return t, true
+}
+
+// Text returns the raw HTML of a node.
+func Text(n *ast.RawHTML, source []byte) string {
+	var b strings.Builder
+	for i := 0; i < n.Segments.Len(); i++ {
+		s := n.Segments.At(i)
+		b.Write(s.Value(source))
+	}
+	return b.String()
+}
+
+// Transform replaces the tags in a tree with the nodes convert returns for
+// them. The nodes between an opening and a closing tag are moved into the
+// node; void tags are replaced on their own. Tags convert returns nil for,
+// and tags that aren't closed, are left alone.
+func Transform(root ast.Node, source []byte, convert func(Tag) ast.Node) {
+	var parents []ast.Node
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && n.Kind() == ast.KindRawHTML {
+			if p := n.Parent(); len(parents) == 0 || parents[len(parents)-1] != p {
+				parents = append(parents, p)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, p := range parents {
+		transformChildren(p, source, convert)
+	}
+}
+
+func transformChildren(parent ast.Node, source []byte, convert func(Tag) ast.Node) {
+	for c := parent.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		raw, ok := c.(*ast.RawHTML)
+		if !ok {
+			c = next
+			continue
+		}
+		tag, ok := ParseTag(Text(raw, source))
+		if !ok || tag.Closing {
+			c = next
+			continue
+		}
+
+		if tag.Void {
+			if n := convert(tag); n != nil {
+				parent.ReplaceChild(parent, c, n)
+			}
+			c = next
+			continue
+		}
+
+		end := closingTag(c, tag.Name, source)
+		if end == nil {
+			c = next
+			continue
+		}
+		n := convert(tag)
+		if n == nil {
+			c = next
+			continue
+		}
+		parent.InsertBefore(parent, c, n)
+		for m := c.NextSibling(); m != end; {
+			following := m.NextSibling()
+			n.AppendChild(n, m)
+			m = following
+		}
+		next = end.NextSibling()
+		parent.RemoveChild(parent, c)
+		parent.RemoveChild(parent, end)
+
+		transformChildren(n, source, convert)
+		c = next
+	}
+}
+
+// closingTag returns the sibling closing the tag of a node, or nil.
+func closingTag(n ast.Node, name string, source []byte) ast.Node {
+	depth := 0
+	for m := n.NextSibling(); m != nil; m = m.NextSibling() {
+		raw, ok := m.(*ast.RawHTML)
+		if !ok {
+			continue
+		}
+		tag, ok := ParseTag(Text(raw, source))
+		switch {
+		case !ok || tag.Name != name || tag.Void:
+		case !tag.Closing:
+			depth++
+		case depth == 0:
+			return m
+		default:
+			depth--
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package inlinehtml_test
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/glamour/v2/internal/inlinehtml"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want inlinehtml.Tag
+	}{
+		{`<kbd>`, inlinehtml.Tag{Name: "kbd"}},
+		{`</KBD>`, inlinehtml.Tag{Name: "kbd", Closing: true}},
+		{`<br>`, inlinehtml.Tag{Name: "br", Void: true}},
+		{`<span/>`, inlinehtml.Tag{Name: "span", Void: true}},
+		{`<a href="https://charm.sh" title='Charm'>`, inlinehtml.Tag{
+			Name:  "a",
+			Attrs: map[string]string{"href": "https://charm.sh", "title": "Charm"},
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.raw, func(t *testing.T) {
+			got, ok := inlinehtml.ParseTag(tc.raw)
+			if !ok {
+				t.Fatalf("expected %q to be parsed", tc.raw)
+			}
+			if got.Name != tc.want.Name || got.Closing != tc.want.Closing || got.Void != tc.want.Void {
+				t.Errorf("expected %+v, got %+v", tc.want, got)
+			}
+			for k, v := range tc.want.Attrs {
+				if got.Attrs[k] != v {
+					t.Errorf("expected attribute %s to be %q, got %q", k, v, got.Attrs[k])
+				}
+			}
+		})
+	}
+
+	for _, raw := range []string{`<!-- comment -->`, `text`} {
+		if _, ok := inlinehtml.ParseTag(raw); ok {
+			t.Errorf("expected %q not to be parsed", raw)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"<b>bold</b>", "Span(b)[Text]"},
+		{"<b>one <b>two</b></b>", "Span(b)[Text Span(b)[Text]]"},
+		{"<b>a <i>b</i> c</b>", "Span(b)[Text Span(i)[Text] Text]"},
+		{"a<br>b", "Text Span(br) Text"},
+		{"<b>unclosed", "RawHTML Text"},
+		{"<span>kept</span>", "RawHTML Text RawHTML"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			source := []byte(tc.in)
+			doc := goldmark.New().Parser().Parse(text.NewReader(source))
+			inlinehtml.Transform(doc, source, func(t inlinehtml.Tag) ast.Node {
+				if t.Name == "span" {
+					return nil
+				}
+				return &inlinehtml.Span{Tag: t.Name}
+			})
+			if got := dump(doc.FirstChild()); got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+// dump describes the children of a node.
+func dump(n ast.Node) string {
+	var parts []string
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		s := c.Kind().String()
+		if span, ok := c.(*inlinehtml.Span); ok {
+			s = "Span(" + span.Tag + ")"
+			if c.HasChildren() {
+				s += "[" + dump(c) + "]"
+			}
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,270 @@
+// Package latex provides a goldmark extension that parses $inline$ and
+// $$display$$ math, and converts TeX to Unicode text.
+package latex
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// ToUnicode converts TeX to lines of Unicode text. Display math may span
+// several lines, with fractions stacked and matrices laid out in rows; inline
+// math is converted to a single line. It reports false if the TeX uses
+// commands that aren't supported.
+func ToUnicode(tex string, display bool) ([]string, bool) {
+	e, err := parse(tex)
+	if err != nil {
+		return nil, false
+	}
+	b, err := layout{display: display}.expr(e)
+	if err != nil {
+		return nil, false
+	}
+	lines := make([]string, len(b.lines))
+	for i, l := range b.lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return lines, true
+}
+
+// Superscript writes text with Unicode superscript characters. It reports
+// false if there isn't one for every character.
+func Superscript(s string) (string, bool) {
+	return toScript(s, superscripts)
+}
+
+// Subscript writes text with Unicode subscript characters. It reports false
+// if there isn't one for every character.
+func Subscript(s string) (string, bool) {
+	return toScript(s, subscripts)
+}
+
+// KindInlineMath is the kind of inline math nodes.
+var KindInlineMath = ast.NewNodeKind("InlineMath")
+
+// An InlineMath node is a formula within text.
+type InlineMath struct {
+	ast.BaseInline
+
+	TeX string
+}
+
+// Kind implements ast.Node.Kind.
+func (n *InlineMath) Kind() ast.NodeKind {
+	return KindInlineMath
+}
+
+// Dump implements ast.Node.Dump.
+func (n *InlineMath) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"TeX": n.TeX,
+	}, nil)
+}
+
+// KindMathBlock is the kind of display math nodes.
+var KindMathBlock = ast.NewNodeKind("MathBlock")
+
+// A MathBlock node is a formula displayed on its own. Its lines hold the
+// TeX.
+type MathBlock struct {
+	ast.BaseBlock
+
+	opening text.Segment
+	closed  bool
+}
+
+// Kind implements ast.Node.Kind.
+func (n *MathBlock) Kind() ast.NodeKind {
+	return KindMathBlock
+}
+
+// IsRaw implements ast.Node.IsRaw.
+func (n *MathBlock) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.Dump.
+func (n *MathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// TeX returns the TeX of the formula.
+func (n *MathBlock) TeX(source []byte) string {
+	var b bytes.Buffer
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		b.Write(line.Value(source))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+var delimiter = []byte("$$")
+
+type mathBlockParser struct{}
+
+// Trigger implements parser.BlockParser.Trigger.
+func (p *mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Open implements parser.BlockParser.Open.
+func (p *mathBlockParser) Open(_ ast.Node, reader text.Reader, _ parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := indentation(line)
+	if pos < 0 || !bytes.HasPrefix(line[pos:], delimiter) {
+		return nil, parser.NoChildren
+	}
+	start := pos + len(delimiter)
+	node := &MathBlock{opening: text.NewSegment(segment.Start+pos, segment.Stop)}
+
+	// $$ formula $$ on a single line
+	rest := util.TrimRightSpace(line[start:])
+	if len(rest) >= len(delimiter) && bytes.HasSuffix(rest, delimiter) {
+		stop := start + len(rest) - len(delimiter)
+		node.Lines().Append(text.NewSegment(segment.Start+start, segment.Start+stop))
+		node.closed = true
+		advanceToEOL(reader, line, segment)
+		return node, parser.NoChildren
+	}
+
+	if !util.IsBlank(rest) {
+		node.Lines().Append(text.NewSegment(segment.Start+start, segment.Stop))
+	}
+	advanceToEOL(reader, line, segment)
+	return node, parser.NoChildren
+}
+
+// advanceToEOL advances the reader to the end of the current line, which
+// doesn't end in a newline at the end of the document.
+func advanceToEOL(reader text.Reader, line []byte, segment text.Segment) {
+	n := segment.Len()
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		n--
+	}
+	reader.Advance(n)
+}
+
+// indentation returns the position of the first non-space character of a line
+// indented by up to three spaces, or -1.
+func indentation(line []byte) int {
+	n := util.TrimLeftSpaceLength(line)
+	if n > 3 || n == len(line) {
+		return -1
+	}
+	return n
+}
+
+// Continue implements parser.BlockParser.Continue.
+func (p *mathBlockParser) Continue(node ast.Node, reader text.Reader, _ parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	if line == nil || node.(*MathBlock).closed || util.IsBlank(line) {
+		return parser.Close
+	}
+	trimmed := util.TrimRightSpace(line)
+	if bytes.HasSuffix(trimmed, delimiter) {
+		stop := len(trimmed) - len(delimiter)
+		if !util.IsBlank(trimmed[:stop]) {
+			node.Lines().Append(text.NewSegment(segment.Start, segment.Start+stop))
+		}
+		advanceToEOL(reader, line, segment)
+		node.(*MathBlock).closed = true
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	return parser.Continue | parser.NoChildren
+}
+
+// Close implements parser.BlockParser.Close. A block that isn't closed
+// before a blank line or the end of the document is a paragraph of text.
+func (p *mathBlockParser) Close(node ast.Node, reader text.Reader, _ parser.Context) {
+	n := node.(*MathBlock)
+	if n.closed {
+		return
+	}
+	para := ast.NewParagraph()
+	para.Lines().Append(n.opening)
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		if i == 0 && line.Stop == n.opening.Stop {
+			continue
+		}
+		para.Lines().Append(line.TrimLeftSpace(reader.Source()))
+	}
+	last := para.Lines().At(para.Lines().Len() - 1)
+	para.Lines().Set(para.Lines().Len()-1, last.TrimRightSpace(reader.Source()))
+	node.Parent().ReplaceChild(node.Parent(), node, para)
+}
+
+// CanInterruptParagraph implements parser.BlockParser.CanInterruptParagraph.
+func (p *mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.CanAcceptIndentedLine.
+func (p *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+type inlineMathParser struct{}
+
+// Trigger implements parser.InlineParser.Trigger.
+func (p *inlineMathParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Parse implements parser.InlineParser.Parse.
+func (p *inlineMathParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	delim := 1
+	if bytes.HasPrefix(line, delimiter) {
+		delim = len(delimiter)
+	}
+	// formulas can't start or end with a space, and the closing $ can't be
+	// followed by a digit. A $ that can't close a formula ends the search,
+	// so prices like $5 and $10 stay text.
+	if len(line) <= delim || util.IsSpace(line[delim]) {
+		return nil
+	}
+
+	for i := delim; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] == '$':
+			if !bytes.HasPrefix(line[i:], line[:delim]) || util.IsSpace(line[i-1]) ||
+				(delim == 1 && i+1 < len(line) && util.IsNumeric(line[i+1])) ||
+				(i+delim < len(line) && line[i+delim] == '$') {
+				return nil
+			}
+			block.Advance(i + delim)
+			return &InlineMath{TeX: string(line[delim:i])}
+		}
+	}
+	return nil
+}
+
+type extender struct{}
+
+// New returns an extension that parses math.
+func New() goldmark.Extender {
+	return &extender{}
+}
+
+// Extend implements goldmark.Extender.Extend.
+func (e *extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(&mathBlockParser{}, 701),
+		),
+		parser.WithInlineParsers(
+			util.Prioritized(&inlineMathParser{}, 150),
+		),
+	)
+}
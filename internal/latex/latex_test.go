@@ -0,0 +1,140 @@
+package latex_test
+
+import (
+	"slices"
+	"testing"
+
+	"charm.land/glamour/v2/internal/latex"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestToUnicode(t *testing.T) {
+	tests := []struct {
+		name    string
+		tex     string
+		display bool
+		want    []string
+	}{
+		{"scripts", `x^2 + y_i^2 = z^{n+1}`, false, []string{"x² + yᵢ² = zⁿ⁺¹"}},
+		{"greek", `\alpha \beta \Gamma`, false, []string{"αβΓ"}},
+		{"operators", `a \leq b \neq c \to \infty`, false, []string{"a ≤ b ≠ c → ∞"}},
+		{"sign", `-x + 1`, false, []string{"−x + 1"}},
+		{"numbers", `12x^{10}`, false, []string{"12x¹⁰"}},
+		{"vulgar fraction", `\frac{1}{2}`, false, []string{"½"}},
+		{"slashed fraction", `\frac{a+b}{c}`, false, []string{"(a + b)/c"}},
+		{"sqrt", `\sqrt{x} + \sqrt[3]{y}`, false, []string{"√x + ∛y"}},
+		{"sqrt of fraction", `\sqrt{\frac{a}{b}}`, false, []string{"√(a/b)"}},
+		{"blackboard", `x \in \mathbb{R}`, false, []string{"x ∈ ℝ"}},
+		{"function", `\sin x`, false, []string{"sin x"}},
+		{"inline matrix", `\begin{bmatrix} a & b \\ c & d \end{bmatrix}`, false, []string{"[a b; c d]"}},
+		{
+			"stacked fraction", `\frac{a+b}{c}`, true,
+			[]string{" a + b", "───────", "   c"},
+		},
+		{
+			"limits", `\sum_{i=1}^{n} i`, true,
+			[]string{" n", " ∑ i", "i=1"},
+		},
+		{
+			"matrix", `\begin{pmatrix} a & b \\ c & d \end{pmatrix}`, true,
+			[]string{"⎛a  b⎞", "⎝c  d⎠"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := latex.ToUnicode(tc.tex, tc.display)
+			if !ok {
+				t.Fatalf("expected %q to be supported", tc.tex)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestToUnicodeUnsupported(t *testing.T) {
+	for _, tex := range []string{`\color{red}{x}`, `\frac{a}`, `{x`, `\begin{tikzpicture}\end{tikzpicture}`} {
+		if got, ok := latex.ToUnicode(tex, false); ok {
+			t.Errorf("expected %q to be unsupported, got %q", tex, got)
+		}
+	}
+}
+
+func TestParser(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		inline []string
+		blocks []string
+	}{
+		{"inline", "Let $x^2$ and $$y$$.", []string{"x^2", "y"}, nil},
+		{"prices", "From $5 to $10.", nil, nil},
+		{"spaces", "$ x $ and $y $", nil, nil},
+		{"escaped", `$a \$ b$`, []string{`a \$ b`}, nil},
+		{"block", "$$\nx = 1\n$$\n", nil, []string{"x = 1"}},
+		{"single line block", "$$x = 1$$\n", nil, []string{"x = 1"}},
+		{"interrupts paragraph", "Text\n$$\nx\n$$\n", nil, []string{"x"}},
+	}
+	md := goldmark.New(goldmark.WithExtensions(latex.New()))
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			src := []byte(tc.in)
+			doc := md.Parser().Parse(text.NewReader(src))
+
+			var inline, blocks []string
+			_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+				if !entering {
+					return ast.WalkContinue, nil
+				}
+				switch n := n.(type) {
+				case *latex.InlineMath:
+					inline = append(inline, n.TeX)
+				case *latex.MathBlock:
+					blocks = append(blocks, n.TeX(src))
+				}
+				return ast.WalkContinue, nil
+			})
+			if !slices.Equal(inline, tc.inline) {
+				t.Errorf("expected inline math %q, got %q", tc.inline, inline)
+			}
+			if !slices.Equal(blocks, tc.blocks) {
+				t.Errorf("expected math blocks %q, got %q", tc.blocks, blocks)
+			}
+		})
+	}
+}
+
+func TestParserEndOfDocument(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(latex.New()))
+	for _, in := range []string{
+		"$$\n\\frac{a}{b}\n$$",
+		"$$x = 1$$",
+		"$$ x = 1\n$$",
+	} {
+		doc := md.Parser().Parse(text.NewReader([]byte(in)))
+		if _, ok := doc.FirstChild().(*latex.MathBlock); !ok || doc.ChildCount() != 1 {
+			t.Errorf("%q: expected a single math block, got %d nodes", in, doc.ChildCount())
+		}
+	}
+}
+
+func TestParserUnclosedBlock(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(latex.New()))
+	in := "$$ is slang for money\n\n# Heading\n\n- list item"
+	source := []byte(in)
+	doc := md.Parser().Parse(text.NewReader(source))
+	var kinds []string
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		kinds = append(kinds, n.Kind().String())
+	}
+	if want := []string{"Paragraph", "Heading", "List"}; !slices.Equal(kinds, want) {
+		t.Fatalf("expected %v, got %v", want, kinds)
+	}
+	if got := string(doc.FirstChild().Text(source)); got != "$$ is slang for money" {
+		t.Errorf("expected the paragraph to keep its text, got %q", got)
+	}
+}
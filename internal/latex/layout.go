@@ -0,0 +1,508 @@
+package latex
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// A box is a block of text with a baseline, the line it gets aligned on
+// when it's put next to other boxes.
+type box struct {
+	lines []string
+	base  int
+}
+
+func textBox(s string) box {
+	return box{lines: []string{s}}
+}
+
+func (b box) width() int {
+	w := 0
+	for _, l := range b.lines {
+		w = max(w, ansi.StringWidth(l))
+	}
+	return w
+}
+
+func (b box) height() int {
+	return len(b.lines)
+}
+
+// pad pads s with spaces to the given width. left is the share of the
+// padding that goes to the left.
+func pad(s string, width int, left float64) string {
+	n := max(width-ansi.StringWidth(s), 0)
+	l := int(float64(n) * left)
+	return strings.Repeat(" ", l) + s + strings.Repeat(" ", n-l)
+}
+
+// hcat puts boxes next to each other, aligning their baselines.
+func hcat(boxes ...box) box {
+	above, below := 0, 0
+	for _, b := range boxes {
+		above = max(above, b.base)
+		below = max(below, b.height()-b.base-1)
+	}
+
+	lines := make([]string, above+below+1)
+	for _, b := range boxes {
+		w := b.width()
+		top := above - b.base
+		for i := range lines {
+			s := ""
+			if j := i - top; j >= 0 && j < b.height() {
+				s = b.lines[j]
+			}
+			lines[i] += pad(s, w, 0)
+		}
+	}
+	return box{lines: lines, base: above}
+}
+
+// vstack puts boxes on top of each other, centered, with the baseline on
+// the given line.
+func vstack(boxes []box, base int) box {
+	w := 0
+	for _, b := range boxes {
+		w = max(w, b.width())
+	}
+	var lines []string
+	for _, b := range boxes {
+		for _, l := range b.lines {
+			lines = append(lines, pad(l, w, 0.5))
+		}
+	}
+	return box{lines: lines, base: base}
+}
+
+// delimit puts delimiters around a box, stretching them to its height.
+func delimit(b box, left, right string) box {
+	var parts []box
+	if left != "" {
+		parts = append(parts, tallDelimiter(left, b))
+	}
+	parts = append(parts, b)
+	if right != "" {
+		parts = append(parts, tallDelimiter(right, b))
+	}
+	return hcat(parts...)
+}
+
+func tallDelimiter(d string, b box) box {
+	h := b.height()
+	pieces, ok := tall[d]
+	if h == 1 || !ok {
+		lines := make([]string, h)
+		for i := range lines {
+			lines[i] = d
+		}
+		return box{lines: lines, base: b.base}
+	}
+
+	lines := make([]string, h)
+	for i := range lines {
+		switch {
+		case i == 0:
+			lines[i] = pieces[0]
+		case i == h-1:
+			lines[i] = pieces[2]
+		case h%2 == 1 && i == h/2:
+			lines[i] = pieces[3]
+		default:
+			lines[i] = pieces[1]
+		}
+	}
+	return box{lines: lines, base: b.base}
+}
+
+// layout lays out formulas. Display math may span several lines, while
+// inline math and scripts are kept on a single line.
+type layout struct {
+	display bool
+	script  bool
+}
+
+// flatten lays out an expression on a single line without any spacing.
+func flatten(e expr) (string, error) {
+	b, err := layout{script: true}.expr(e)
+	if err != nil {
+		return "", err
+	}
+	return b.lines[0], nil
+}
+
+func (l layout) expr(e expr) (box, error) {
+	var parts []box
+	spaced := false
+	space := func() {
+		if len(parts) > 0 && !spaced {
+			parts = append(parts, textBox(" "))
+			spaced = true
+		}
+	}
+
+	pending := false
+	prev := open
+	for i, a := range e {
+		b, err := l.atom(a)
+		if err != nil {
+			return box{}, err
+		}
+
+		c := a.class
+		if a.kind != kText {
+			c = ord
+		}
+		// signs
+		if c == bin && (i == 0 || prev == bin || prev == rel || prev == open || prev == punct) {
+			c = ord
+		}
+
+		if !l.script {
+			if pending || c == bin || c == rel {
+				space()
+			}
+			pending = false
+			switch c {
+			case bin, rel, punct, bigop:
+				pending = true
+			case fn:
+				pending = i+1 < len(e) && e[i+1].class != open
+			}
+		}
+
+		parts = append(parts, b)
+		spaced = strings.HasSuffix(b.lines[b.base], " ")
+		prev = c
+	}
+	if len(parts) == 0 {
+		return textBox(""), nil
+	}
+	return hcat(parts...), nil
+}
+
+func (l layout) atom(a *atom) (box, error) {
+	var b box
+	var err error
+	switch a.kind {
+	case kText:
+		b = textBox(a.text)
+	case kGroup:
+		b, err = l.expr(a.args[0])
+	case kFrac:
+		b, err = l.frac(a)
+	case kBinom:
+		b, err = l.binom(a)
+	case kSqrt:
+		b, err = l.sqrt(a)
+	case kMatrix:
+		b, err = l.matrix(a)
+	case kDelimited:
+		b, err = l.expr(a.args[0])
+		b = delimit(b, a.delims[0], a.delims[1])
+	}
+	if err != nil {
+		return box{}, err
+	}
+	return l.attachScripts(b, a)
+}
+
+// operand lays out an operand of a slashed fraction or inline root, wrapping
+// it in parentheses unless it's a single symbol or number.
+func (l layout) operand(e expr) (string, error) {
+	b, err := l.expr(e)
+	if err != nil {
+		return "", err
+	}
+	if len(e) != 1 || e[0].kind != kText || e[0].hasSup || e[0].hasSub {
+		return "(" + b.lines[0] + ")", nil
+	}
+	return b.lines[0], nil
+}
+
+func (l layout) frac(a *atom) (box, error) {
+	if !l.display {
+		num, err := l.operand(a.args[0])
+		if err != nil {
+			return box{}, err
+		}
+		den, err := l.operand(a.args[1])
+		if err != nil {
+			return box{}, err
+		}
+		if f, ok := fractions[num+"/"+den]; ok {
+			return textBox(f), nil
+		}
+		return textBox(num + "/" + den), nil
+	}
+
+	num, err := l.expr(a.args[0])
+	if err != nil {
+		return box{}, err
+	}
+	den, err := l.expr(a.args[1])
+	if err != nil {
+		return box{}, err
+	}
+	rule := textBox(strings.Repeat("─", max(num.width(), den.width())+2))
+	return vstack([]box{num, rule, den}, num.height()), nil
+}
+
+func (l layout) binom(a *atom) (box, error) {
+	if !l.display {
+		n, err := l.expr(a.args[0])
+		if err != nil {
+			return box{}, err
+		}
+		k, err := l.expr(a.args[1])
+		if err != nil {
+			return box{}, err
+		}
+		return textBox("C(" + n.lines[0] + ", " + k.lines[0] + ")"), nil
+	}
+
+	n, err := l.expr(a.args[0])
+	if err != nil {
+		return box{}, err
+	}
+	k, err := l.expr(a.args[1])
+	if err != nil {
+		return box{}, err
+	}
+	b := vstack([]box{n, k}, n.height()-1)
+	return delimit(b, "(", ")"), nil
+}
+
+func (l layout) sqrt(a *atom) (box, error) {
+	sign := "√"
+	if len(a.args) > 1 {
+		index, err := flatten(a.args[1])
+		if err != nil {
+			return box{}, err
+		}
+		switch index {
+		case "3":
+			sign = "∛"
+		case "4":
+			sign = "∜"
+		default:
+			if s, ok := toScript(index, superscripts); ok {
+				sign = s + sign
+			} else {
+				sign = "(" + index + ")" + sign
+			}
+		}
+	}
+
+	if !l.display {
+		arg, err := l.operand(a.args[0])
+		if err != nil {
+			return box{}, err
+		}
+		return textBox(sign + arg), nil
+	}
+
+	arg, err := l.expr(a.args[0])
+	if err != nil {
+		return box{}, err
+	}
+	if arg.height() > 1 {
+		return hcat(textBox(sign), delimit(arg, "(", ")")), nil
+	}
+	// draw a bar over the radicand
+	w := ansi.StringWidth(sign)
+	return box{
+		lines: []string{
+			strings.Repeat(" ", w) + strings.Repeat("_", arg.width()),
+			sign + arg.lines[0],
+		},
+		base: 1,
+	}, nil
+}
+
+func (l layout) matrix(a *atom) (box, error) {
+	if !l.display {
+		rows := make([]string, len(a.rows))
+		for i, row := range a.rows {
+			cells := make([]string, len(row))
+			for j, cell := range row {
+				b, err := l.expr(cell)
+				if err != nil {
+					return box{}, err
+				}
+				cells[j] = b.lines[0]
+			}
+			rows[i] = strings.TrimSpace(strings.Join(cells, " "))
+		}
+		return textBox(a.delims[0] + strings.Join(rows, "; ") + a.delims[1]), nil
+	}
+
+	var cells [][]box
+	var widths []int
+	for _, row := range a.rows {
+		var boxes []box
+		for j, cell := range row {
+			b, err := l.expr(cell)
+			if err != nil {
+				return box{}, err
+			}
+			boxes = append(boxes, b)
+			if j >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[j] = max(widths[j], b.width())
+		}
+		cells = append(cells, boxes)
+	}
+
+	var rows []box
+	for _, row := range cells {
+		var parts []box
+		for j := range widths {
+			b := textBox("")
+			if j < len(row) {
+				b = row[j]
+			}
+			align := 0.5
+			gap := "  "
+			switch {
+			case a.align == alignLeft:
+				align = 0
+			case a.align == alignEquations && j%2 == 0:
+				align, gap = 1, " "
+			case a.align == alignEquations:
+				align = 0
+			}
+			if j > 0 {
+				parts = append(parts, textBox(gap))
+			}
+			for k := range b.lines {
+				b.lines[k] = pad(b.lines[k], widths[j], align)
+			}
+			parts = append(parts, b)
+		}
+		rows = append(rows, hcat(parts...))
+	}
+
+	h := 0
+	for _, r := range rows {
+		h += r.height()
+	}
+	b := vstack(rows, (h-1)/2)
+	for i := range b.lines {
+		b.lines[i] = pad(b.lines[i], b.width(), 0)
+	}
+	return delimit(b, a.delims[0], a.delims[1]), nil
+}
+
+// attachScripts attaches the super- and subscripts of an atom to its box.
+// Scripts are written with Unicode characters if there are any for all of
+// their characters; otherwise they're put above and below the atom in
+// display math, or written as ^(…) and _(…).
+func (l layout) attachScripts(b box, a *atom) (box, error) {
+	if !a.hasSup && !a.hasSub {
+		return b, nil
+	}
+
+	var sup, sub string
+	var err error
+	if a.hasSup {
+		if sup, err = flatten(a.sup); err != nil {
+			return box{}, err
+		}
+	}
+	if a.hasSub {
+		if sub, err = flatten(a.sub); err != nil {
+			return box{}, err
+		}
+	}
+
+	if a.class == bigop && a.limits && l.display {
+		var parts []box
+		base := b.base
+		if a.hasSup {
+			parts = append(parts, textBox(sup))
+			base++
+		}
+		parts = append(parts, b)
+		if a.hasSub {
+			parts = append(parts, textBox(sub))
+		}
+		return vstack(parts, base), nil
+	}
+
+	suffix := ""
+	if s, ok := toScript(sub, subscripts); a.hasSub && ok {
+		suffix += s
+		sub, a = "", withoutSub(a)
+	}
+	if s, ok := toScript(sup, superscripts); a.hasSup && ok {
+		suffix += s
+		sup, a = "", withoutSup(a)
+	}
+	b = hcat(b, textBox(suffix))
+	if !a.hasSup && !a.hasSub {
+		return b, nil
+	}
+
+	if l.display {
+		col := box{base: b.base}
+		if a.hasSup {
+			col.lines = append(col.lines, sup)
+			col.base++
+		}
+		for range b.lines {
+			col.lines = append(col.lines, "")
+		}
+		if a.hasSub {
+			col.lines = append(col.lines, sub)
+		}
+		return hcat(b, col), nil
+	}
+
+	script := func(mark, s string) string {
+		if ansi.StringWidth(s) == 1 {
+			return mark + s
+		}
+		return mark + "(" + s + ")"
+	}
+	if a.hasSub {
+		suffix = script("_", sub)
+	} else {
+		suffix = ""
+	}
+	if a.hasSup {
+		suffix += script("^", sup)
+	}
+	return hcat(b, textBox(suffix)), nil
+}
+
+func withoutSub(a *atom) *atom {
+	c := *a
+	c.sub, c.hasSub = nil, false
+	return &c
+}
+
+func withoutSup(a *atom) *atom {
+	c := *a
+	c.sup, c.hasSup = nil, false
+	return &c
+}
+
+// toScript writes s with super- or subscript characters, if there are any
+// for all of its characters.
+func toScript(s string, m map[rune]rune) (string, bool) {
+	if s == "" {
+		return "", false
+	}
+	var b strings.Builder
+	for _, r := range s {
+		c, ok := m[r]
+		if !ok {
+			return "", false
+		}
+		b.WriteRune(c)
+	}
+	return b.String(), true
+}
@@ -0,0 +1,596 @@
+package latex
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errUnsupported is returned for TeX that can't be converted.
+var errUnsupported = errors.New("unsupported TeX")
+
+type tokenKind int
+
+const (
+	tChar tokenKind = iota
+	tCmd
+	tSpace
+	tOpen
+	tClose
+	tSup
+	tSub
+	tAmp
+	tRowSep
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits TeX into tokens. Comments are dropped.
+func tokenize(s string) []token {
+	var toks []token
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '\\':
+			j := i + 1
+			for j < len(s) && isLetter(s[j]) {
+				j++
+			}
+			if j == i+1 && j < len(s) {
+				// control symbols like \{ and \,
+				c, n := utf8.DecodeRuneInString(s[j:])
+				j += n
+				if c == '\\' {
+					toks = append(toks, token{kind: tRowSep})
+				} else {
+					toks = append(toks, token{kind: tCmd, text: string(c)})
+				}
+			} else {
+				toks = append(toks, token{kind: tCmd, text: s[i+1 : j]})
+			}
+			i = j
+			continue
+		case r == '%':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			continue
+		case r == '{':
+			toks = append(toks, token{kind: tOpen})
+		case r == '}':
+			toks = append(toks, token{kind: tClose})
+		case r == '^':
+			toks = append(toks, token{kind: tSup})
+		case r == '_':
+			toks = append(toks, token{kind: tSub})
+		case r == '&':
+			toks = append(toks, token{kind: tAmp})
+		case unicode.IsSpace(r):
+			toks = append(toks, token{kind: tSpace})
+		default:
+			toks = append(toks, token{kind: tChar, text: string(r)})
+		}
+		i += size
+	}
+	return toks
+}
+
+// isNumber reports whether an atom is a number without scripts.
+func isNumber(a *atom) bool {
+	if a.kind != kText || a.hasSup || a.hasSub || a.text == "" {
+		return false
+	}
+	for _, r := range a.text {
+		if !unicode.IsDigit(r) && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+type atomKind int
+
+const (
+	kText atomKind = iota
+	kGroup
+	kFrac
+	kBinom
+	kSqrt
+	kMatrix
+	kDelimited
+)
+
+// alignment determines how the cells of a matrix are aligned.
+type alignment int
+
+const (
+	alignCenter alignment = iota
+	alignLeft
+	// alignEquations aligns the columns of aligned equations, where every
+	// other column is right-aligned.
+	alignEquations
+)
+
+// An atom is a single item of a formula along with its scripts.
+type atom struct {
+	kind   atomKind
+	text   string
+	class  class
+	limits bool
+
+	// args are the arguments of groups, fractions, binomials, roots and
+	// delimited expressions
+	args []expr
+	// rows, delims and align describe matrices; delims also hold the
+	// delimiters of delimited expressions
+	rows   [][]expr
+	delims [2]string
+	align  alignment
+
+	sup, sub       expr
+	hasSup, hasSub bool
+}
+
+type expr []*atom
+
+type texParser struct {
+	toks []token
+	pos  int
+}
+
+// parse parses a formula. Formulas with several rows, like aligned
+// equations, are returned as a matrix.
+func parse(tex string) (expr, error) {
+	p := &texParser{toks: tokenize(tex)}
+	rows, err := p.parseRows("")
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 1 && len(rows[0]) == 1 {
+		return rows[0][0], nil
+	}
+	return expr{{kind: kMatrix, rows: rows, align: alignEquations}}, nil
+}
+
+func (p *texParser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *texParser) skipSpace() {
+	for t, ok := p.peek(); ok && t.kind == tSpace; t, ok = p.peek() {
+		p.pos++
+	}
+}
+
+// parseRows parses the rows of a matrix environment up to its \end, or of
+// the whole formula if env is empty.
+func (p *texParser) parseRows(env string) ([][]expr, error) {
+	var rows [][]expr
+	var row []expr
+	for {
+		cell, err := p.parseExpr(false)
+		if err != nil {
+			return nil, err
+		}
+		row = append(row, cell)
+
+		t, ok := p.peek()
+		if !ok {
+			if env != "" {
+				return nil, errUnsupported
+			}
+			break
+		}
+		p.pos++
+		switch {
+		case t.kind == tAmp:
+			continue
+		case t.kind == tRowSep:
+			rows = append(rows, row)
+			row = nil
+			continue
+		case t.kind == tCmd && t.text == "end" && env != "":
+			name, err := p.rawArg()
+			if err != nil || name != env {
+				return nil, errUnsupported
+			}
+		default:
+			return nil, errUnsupported
+		}
+		break
+	}
+
+	// a trailing \\ doesn't start another row
+	if len(rows) == 0 || len(row) > 1 || len(row[0]) > 0 {
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseExpr parses atoms up to the end of the current group, cell or
+// delimited expression.
+func (p *texParser) parseExpr(inGroup bool) (expr, error) {
+	var e expr
+	for {
+		t, ok := p.peek()
+		if !ok {
+			if inGroup {
+				return nil, errUnsupported
+			}
+			return e, nil
+		}
+
+		switch {
+		case t.kind == tClose:
+			if !inGroup {
+				return nil, errUnsupported
+			}
+			return e, nil
+		case t.kind == tAmp, t.kind == tRowSep,
+			t.kind == tCmd && (t.text == "end" || t.text == "right"):
+			return e, nil
+		case t.kind == tSpace:
+			p.pos++
+		case t.kind == tSup, t.kind == tSub:
+			p.pos++
+			arg, err := p.parseArg()
+			if err != nil {
+				return nil, err
+			}
+			if len(e) == 0 {
+				e = append(e, &atom{kind: kText})
+			}
+			last := e[len(e)-1]
+			if t.kind == tSup {
+				if last.hasSup {
+					return nil, errUnsupported
+				}
+				last.sup, last.hasSup = arg, true
+			} else {
+				if last.hasSub {
+					return nil, errUnsupported
+				}
+				last.sub, last.hasSub = arg, true
+			}
+		default:
+			a, err := p.parseAtom()
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case a == nil:
+			case isNumber(a) && len(e) > 0 && isNumber(e[len(e)-1]):
+				// numbers are kept together
+				e[len(e)-1].text += a.text
+			default:
+				e = append(e, a)
+			}
+		}
+	}
+}
+
+// parseArg parses the argument of a command or script: a group or a single
+// atom.
+func (p *texParser) parseArg() (expr, error) {
+	p.skipSpace()
+	t, ok := p.peek()
+	if !ok {
+		return nil, errUnsupported
+	}
+	if t.kind == tOpen {
+		p.pos++
+		e, err := p.parseExpr(true)
+		if err != nil {
+			return nil, err
+		}
+		p.pos++
+		return e, nil
+	}
+	if t.kind != tChar && t.kind != tCmd {
+		return nil, errUnsupported
+	}
+	a, err := p.parseAtom()
+	if err != nil || a == nil {
+		return nil, errUnsupported
+	}
+	return expr{a}, nil
+}
+
+// rawArg returns the text of a group argument as it's written, keeping its
+// spaces.
+func (p *texParser) rawArg() (string, error) {
+	p.skipSpace()
+	if t, ok := p.peek(); !ok || t.kind != tOpen {
+		return "", errUnsupported
+	}
+	p.pos++
+
+	var b strings.Builder
+	for depth := 0; ; {
+		t, ok := p.peek()
+		if !ok {
+			return "", errUnsupported
+		}
+		p.pos++
+		switch t.kind {
+		case tChar:
+			b.WriteString(t.text)
+		case tSpace:
+			b.WriteByte(' ')
+		case tCmd:
+			s, ok := symbols[t.text]
+			if !ok || s.class != ord {
+				return "", errUnsupported
+			}
+			b.WriteString(s.text)
+		case tOpen:
+			depth++
+		case tClose:
+			if depth == 0 {
+				return b.String(), nil
+			}
+			depth--
+		default:
+			return "", errUnsupported
+		}
+	}
+}
+
+// optionalArg parses an optional argument in brackets, such as the index of
+// a root.
+func (p *texParser) optionalArg() (expr, bool, error) {
+	p.skipSpace()
+	if t, ok := p.peek(); !ok || t.kind != tChar || t.text != "[" {
+		return nil, false, nil
+	}
+	depth := 0
+	for j := p.pos + 1; j < len(p.toks); j++ {
+		switch t := p.toks[j]; {
+		case t.kind == tOpen:
+			depth++
+		case t.kind == tClose:
+			depth--
+		case depth == 0 && t.kind == tChar && t.text == "]":
+			sub := &texParser{toks: p.toks[p.pos+1 : j]}
+			e, err := sub.parseExpr(false)
+			if err != nil || sub.pos < len(sub.toks) {
+				return nil, false, errUnsupported
+			}
+			p.pos = j + 1
+			return e, true, nil
+		}
+	}
+	return nil, false, errUnsupported
+}
+
+// delimiter parses the delimiter following \left or \right.
+func (p *texParser) delimiter() (string, error) {
+	p.skipSpace()
+	t, ok := p.peek()
+	if !ok {
+		return "", errUnsupported
+	}
+	p.pos++
+	name := t.text
+	if t.kind == tCmd {
+		name = `\` + name
+	}
+	d, ok := delimiters[name]
+	if !ok || (t.kind != tChar && t.kind != tCmd) {
+		return "", errUnsupported
+	}
+	return d, nil
+}
+
+// parseAtom parses the atom at the current token. Commands that don't
+// produce any output return nil.
+func (p *texParser) parseAtom() (*atom, error) {
+	t, _ := p.peek()
+	p.pos++
+
+	switch t.kind {
+	case tOpen:
+		e, err := p.parseExpr(true)
+		if err != nil {
+			return nil, err
+		}
+		p.pos++
+		return &atom{kind: kGroup, args: []expr{e}}, nil
+	case tChar:
+		r, _ := utf8.DecodeRuneInString(t.text)
+		if s, ok := chars[r]; ok {
+			return &atom{kind: kText, text: s.text, class: s.class}, nil
+		}
+		return &atom{kind: kText, text: t.text}, nil
+	case tCmd:
+		return p.parseCommand(t.text)
+	default:
+		return nil, errUnsupported
+	}
+}
+
+func (p *texParser) parseCommand(name string) (*atom, error) {
+	if s, ok := symbols[name]; ok {
+		return &atom{kind: kText, text: s.text, class: s.class, limits: s.limits}, nil
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac", "binom", "dbinom", "tbinom":
+		num, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		den, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		kind := kFrac
+		if strings.HasSuffix(name, "binom") {
+			kind = kBinom
+		}
+		return &atom{kind: kind, args: []expr{num, den}}, nil
+
+	case "sqrt":
+		index, ok, err := p.optionalArg()
+		if err != nil {
+			return nil, err
+		}
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		a := &atom{kind: kSqrt, args: []expr{arg}}
+		if ok {
+			a.args = append(a.args, index)
+		}
+		return a, nil
+
+	case "text", "textrm", "textbf", "textit", "textsf", "texttt", "mbox":
+		s, err := p.rawArg()
+		if err != nil {
+			return nil, err
+		}
+		return &atom{kind: kText, text: s}, nil
+
+	case "operatorname":
+		s, err := p.rawArg()
+		if err != nil {
+			return nil, err
+		}
+		return &atom{kind: kText, text: s, class: fn}, nil
+
+	case "mathbb":
+		s, err := p.rawArg()
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		for _, r := range s {
+			if bb, ok := blackboard[r]; ok {
+				b.WriteString(bb)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		return &atom{kind: kText, text: b.String()}, nil
+
+	case "left":
+		left, err := p.delimiter()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.parseExpr(false)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.peek(); !ok || t.kind != tCmd || t.text != "right" {
+			return nil, errUnsupported
+		}
+		p.pos++
+		right, err := p.delimiter()
+		if err != nil {
+			return nil, err
+		}
+		return &atom{kind: kDelimited, args: []expr{body}, delims: [2]string{left, right}}, nil
+
+	case "begin":
+		env, err := p.rawArg()
+		if err != nil {
+			return nil, err
+		}
+		delims, ok := matrices[env]
+		if !ok {
+			return nil, errUnsupported
+		}
+		if env == "array" {
+			// the column specification
+			if _, err := p.rawArg(); err != nil {
+				return nil, err
+			}
+		}
+		rows, err := p.parseRows(env)
+		if err != nil {
+			return nil, err
+		}
+		a := &atom{kind: kMatrix, rows: rows, delims: delims}
+		switch env {
+		case "cases":
+			a.align = alignLeft
+		case "aligned", "align", "align*":
+			a.align = alignEquations
+		}
+		return a, nil
+
+	case "not":
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		s, err := flatten(arg)
+		if err != nil {
+			return nil, err
+		}
+		switch s {
+		case "=":
+			s = "≠"
+		case "∈":
+			s = "∉"
+		case "≡":
+			s = "≢"
+		case "⊂":
+			s = "⊄"
+		default:
+			s += "̸"
+		}
+		return &atom{kind: kText, text: s, class: rel}, nil
+
+	case "displaystyle", "textstyle", "scriptstyle", "limits", "nolimits":
+		return nil, nil
+	}
+
+	if accent, ok := accents[name]; ok {
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		s, err := flatten(arg)
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		switch name {
+		case "overline", "underline":
+			for _, r := range s {
+				b.WriteRune(r)
+				b.WriteString(accent)
+			}
+		default:
+			if utf8.RuneCountInString(s) != 1 {
+				return nil, errUnsupported
+			}
+			b.WriteString(s + accent)
+		}
+		return &atom{kind: kText, text: b.String()}, nil
+	}
+
+	if fonts[name] {
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		return &atom{kind: kGroup, args: []expr{arg}}, nil
+	}
+
+	return nil, errUnsupported
+}
@@ -0,0 +1,227 @@
+package latex
+
+// class determines the spacing around an atom.
+type class int
+
+const (
+	ord class = iota
+	bin
+	rel
+	open
+	closing
+	punct
+	fn
+	bigop
+)
+
+type symbol struct {
+	text  string
+	class class
+	// limits is set for operators whose scripts go above and below them in
+	// display math.
+	limits bool
+}
+
+// symbols maps TeX commands to the Unicode characters they stand for.
+var symbols = map[string]symbol{
+	// lowercase Greek
+	"alpha": {text: "α"}, "beta": {text: "β"}, "gamma": {text: "γ"},
+	"delta": {text: "δ"}, "epsilon": {text: "ϵ"}, "varepsilon": {text: "ε"},
+	"zeta": {text: "ζ"}, "eta": {text: "η"}, "theta": {text: "θ"},
+	"vartheta": {text: "ϑ"}, "iota": {text: "ι"}, "kappa": {text: "κ"},
+	"lambda": {text: "λ"}, "mu": {text: "μ"}, "nu": {text: "ν"},
+	"xi": {text: "ξ"}, "omicron": {text: "ο"}, "pi": {text: "π"},
+	"varpi": {text: "ϖ"}, "rho": {text: "ρ"}, "varrho": {text: "ϱ"},
+	"sigma": {text: "σ"}, "varsigma": {text: "ς"}, "tau": {text: "τ"},
+	"upsilon": {text: "υ"}, "phi": {text: "ϕ"}, "varphi": {text: "φ"},
+	"chi": {text: "χ"}, "psi": {text: "ψ"}, "omega": {text: "ω"},
+
+	// uppercase Greek
+	"Gamma": {text: "Γ"}, "Delta": {text: "Δ"}, "Theta": {text: "Θ"},
+	"Lambda": {text: "Λ"}, "Xi": {text: "Ξ"}, "Pi": {text: "Π"},
+	"Sigma": {text: "Σ"}, "Upsilon": {text: "Υ"}, "Phi": {text: "Φ"},
+	"Psi": {text: "Ψ"}, "Omega": {text: "Ω"},
+
+	// binary operators
+	"pm": {"±", bin, false}, "mp": {"∓", bin, false}, "times": {"×", bin, false},
+	"div": {"÷", bin, false}, "cdot": {"⋅", bin, false}, "ast": {"∗", bin, false},
+	"star": {"⋆", bin, false}, "circ": {"∘", bin, false}, "bullet": {"∙", bin, false},
+	"cap": {"∩", bin, false}, "cup": {"∪", bin, false}, "setminus": {"∖", bin, false},
+	"oplus": {"⊕", bin, false}, "otimes": {"⊗", bin, false}, "wedge": {"∧", bin, false},
+	"land": {"∧", bin, false}, "vee": {"∨", bin, false}, "lor": {"∨", bin, false},
+
+	// relations
+	"leq": {"≤", rel, false}, "le": {"≤", rel, false}, "geq": {"≥", rel, false},
+	"ge": {"≥", rel, false}, "neq": {"≠", rel, false}, "ne": {"≠", rel, false},
+	"approx": {"≈", rel, false}, "equiv": {"≡", rel, false}, "sim": {"∼", rel, false},
+	"simeq": {"≃", rel, false}, "cong": {"≅", rel, false}, "propto": {"∝", rel, false},
+	"in": {"∈", rel, false}, "notin": {"∉", rel, false}, "ni": {"∋", rel, false},
+	"subset": {"⊂", rel, false}, "supset": {"⊃", rel, false},
+	"subseteq": {"⊆", rel, false}, "supseteq": {"⊇", rel, false},
+	"to": {"→", rel, false}, "rightarrow": {"→", rel, false}, "leftarrow": {"←", rel, false},
+	"gets": {"←", rel, false}, "leftrightarrow": {"↔", rel, false},
+	"Rightarrow": {"⇒", rel, false}, "Leftarrow": {"⇐", rel, false},
+	"Leftrightarrow": {"⇔", rel, false}, "implies": {"⟹", rel, false},
+	"iff": {"⟺", rel, false}, "mapsto": {"↦", rel, false},
+	"ll": {"≪", rel, false}, "gg": {"≫", rel, false}, "perp": {"⊥", rel, false},
+	"parallel": {"∥", rel, false}, "mid": {"∣", rel, false},
+
+	// other symbols
+	"infty": {text: "∞"}, "partial": {text: "∂"}, "nabla": {text: "∇"},
+	"forall": {text: "∀"}, "exists": {text: "∃"}, "nexists": {text: "∄"},
+	"emptyset": {text: "∅"}, "varnothing": {text: "∅"}, "neg": {text: "¬"},
+	"lnot": {text: "¬"}, "hbar": {text: "ℏ"}, "ell": {text: "ℓ"},
+	"Re": {text: "ℜ"}, "Im": {text: "ℑ"}, "aleph": {text: "ℵ"},
+	"angle": {text: "∠"}, "triangle": {text: "△"}, "prime": {text: "′"},
+	"cdots": {text: "⋯"}, "ldots": {text: "…"}, "dots": {text: "…"},
+	"vdots": {text: "⋮"}, "ddots": {text: "⋱"}, "degree": {text: "°"},
+	"|": {text: "‖"}, "%": {text: "%"}, "$": {text: "$"}, "#": {text: "#"},
+	"&": {text: "&"}, "_": {text: "_"},
+
+	// delimiters
+	"{": {"{", open, false}, "}": {"}", closing, false},
+	"langle": {"⟨", open, false}, "rangle": {"⟩", closing, false},
+	"lfloor": {"⌊", open, false}, "rfloor": {"⌋", closing, false},
+	"lceil": {"⌈", open, false}, "rceil": {"⌉", closing, false},
+	"lvert": {"|", open, false}, "rvert": {"|", closing, false},
+	"lVert": {"‖", open, false}, "rVert": {"‖", closing, false},
+
+	// spacing
+	",": {text: " "}, ":": {text: " "}, ";": {text: " "}, " ": {text: " "},
+	"quad": {text: " "}, "qquad": {text: "  "}, "!": {text: ""},
+
+	// large operators
+	"sum": {"∑", bigop, true}, "prod": {"∏", bigop, true}, "coprod": {"∐", bigop, true},
+	"bigcup": {"⋃", bigop, true}, "bigcap": {"⋂", bigop, true},
+	"bigoplus": {"⨁", bigop, true}, "bigotimes": {"⨂", bigop, true},
+	"int": {"∫", bigop, false}, "iint": {"∬", bigop, false},
+	"iiint": {"∭", bigop, false}, "oint": {"∮", bigop, false},
+	"lim": {"lim", bigop, true}, "limsup": {"lim sup", bigop, true},
+	"liminf": {"lim inf", bigop, true}, "max": {"max", bigop, true},
+	"min": {"min", bigop, true}, "sup": {"sup", bigop, true},
+	"inf": {"inf", bigop, true}, "det": {"det", bigop, true},
+	"gcd": {"gcd", bigop, true}, "Pr": {"Pr", bigop, true},
+
+	// functions
+	"sin": {"sin", fn, false}, "cos": {"cos", fn, false}, "tan": {"tan", fn, false},
+	"cot": {"cot", fn, false}, "sec": {"sec", fn, false}, "csc": {"csc", fn, false},
+	"arcsin": {"arcsin", fn, false}, "arccos": {"arccos", fn, false},
+	"arctan": {"arctan", fn, false}, "sinh": {"sinh", fn, false},
+	"cosh": {"cosh", fn, false}, "tanh": {"tanh", fn, false},
+	"log": {"log", fn, false}, "ln": {"ln", fn, false}, "lg": {"lg", fn, false},
+	"exp": {"exp", fn, false}, "deg": {"deg", fn, false}, "dim": {"dim", fn, false},
+	"ker": {"ker", fn, false}, "hom": {"hom", fn, false}, "arg": {"arg", fn, false},
+}
+
+// chars maps characters that are written differently in math.
+var chars = map[rune]symbol{
+	'+':  {"+", bin, false},
+	'-':  {"−", bin, false},
+	'*':  {"∗", bin, false},
+	'/':  {"/", ord, false},
+	'=':  {"=", rel, false},
+	'<':  {"<", rel, false},
+	'>':  {">", rel, false},
+	':':  {":", rel, false},
+	',':  {",", punct, false},
+	';':  {";", punct, false},
+	'\'': {"′", ord, false},
+	'(':  {"(", open, false},
+	'[':  {"[", open, false},
+	')':  {")", closing, false},
+	']':  {"]", closing, false},
+}
+
+// accents maps accent commands to combining characters.
+var accents = map[string]string{
+	"hat":       "̂",
+	"widehat":   "̂",
+	"bar":       "̄",
+	"overline":  "̅",
+	"vec":       "⃗",
+	"dot":       "̇",
+	"ddot":      "̈",
+	"tilde":     "̃",
+	"widetilde": "̃",
+	"underline": "̲",
+}
+
+// fonts are the commands that change the font of their argument. Terminals
+// can't change fonts, so only their argument is rendered.
+var fonts = map[string]bool{
+	"mathrm": true, "mathit": true, "mathbf": true, "mathsf": true,
+	"mathtt": true, "mathcal": true, "boldsymbol": true,
+}
+
+// blackboard maps letters to their double-struck form used by \mathbb.
+var blackboard = map[rune]string{
+	'C': "ℂ", 'H': "ℍ", 'N': "ℕ", 'P': "ℙ", 'Q': "ℚ", 'R': "ℝ", 'Z': "ℤ",
+}
+
+// delimiters maps the delimiters following \left and \right.
+var delimiters = map[string]string{
+	"(": "(", ")": ")", "[": "[", "]": "]", "|": "|", ".": "",
+	`\{`: "{", `\}`: "}", `\|`: "‖", `\langle`: "⟨", `\rangle`: "⟩",
+	`\lfloor`: "⌊", `\rfloor`: "⌋", `\lceil`: "⌈", `\rceil`: "⌉",
+	`\vert`: "|", `\Vert`: "‖", `\lvert`: "|", `\rvert`: "|",
+}
+
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶',
+	'7': '⁷', '8': '⁸', '9': '⁹', '+': '⁺', '-': '⁻', '−': '⁻', '=': '⁼',
+	'(': '⁽', ')': '⁾', '′': '′',
+	'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'f': 'ᶠ', 'g': 'ᵍ',
+	'h': 'ʰ', 'i': 'ⁱ', 'j': 'ʲ', 'k': 'ᵏ', 'l': 'ˡ', 'm': 'ᵐ', 'n': 'ⁿ',
+	'o': 'ᵒ', 'p': 'ᵖ', 'r': 'ʳ', 's': 'ˢ', 't': 'ᵗ', 'u': 'ᵘ', 'v': 'ᵛ',
+	'w': 'ʷ', 'x': 'ˣ', 'y': 'ʸ', 'z': 'ᶻ',
+	'A': 'ᴬ', 'B': 'ᴮ', 'D': 'ᴰ', 'E': 'ᴱ', 'G': 'ᴳ', 'H': 'ᴴ', 'I': 'ᴵ',
+	'J': 'ᴶ', 'K': 'ᴷ', 'L': 'ᴸ', 'M': 'ᴹ', 'N': 'ᴺ', 'O': 'ᴼ', 'P': 'ᴾ',
+	'R': 'ᴿ', 'T': 'ᵀ', 'U': 'ᵁ', 'V': 'ⱽ', 'W': 'ᵂ',
+	'β': 'ᵝ', 'γ': 'ᵞ', 'δ': 'ᵟ', 'θ': 'ᶿ', 'φ': 'ᵠ', 'χ': 'ᵡ',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆',
+	'7': '₇', '8': '₈', '9': '₉', '+': '₊', '-': '₋', '−': '₋', '=': '₌',
+	'(': '₍', ')': '₎',
+	'a': 'ₐ', 'e': 'ₑ', 'h': 'ₕ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ', 'l': 'ₗ',
+	'm': 'ₘ', 'n': 'ₙ', 'o': 'ₒ', 'p': 'ₚ', 'r': 'ᵣ', 's': 'ₛ', 't': 'ₜ',
+	'u': 'ᵤ', 'v': 'ᵥ', 'x': 'ₓ',
+	'β': 'ᵦ', 'γ': 'ᵧ', 'ρ': 'ᵨ', 'φ': 'ᵩ', 'χ': 'ᵪ',
+}
+
+// fractions are the vulgar fractions Unicode has characters for.
+var fractions = map[string]string{
+	"1/2": "½", "1/3": "⅓", "2/3": "⅔", "1/4": "¼", "3/4": "¾",
+	"1/5": "⅕", "2/5": "⅖", "3/5": "⅗", "4/5": "⅘", "1/6": "⅙",
+	"5/6": "⅚", "1/7": "⅐", "1/8": "⅛", "3/8": "⅜", "5/8": "⅝",
+	"7/8": "⅞", "1/9": "⅑", "1/10": "⅒",
+}
+
+// matrices maps matrix environments to their delimiters.
+var matrices = map[string][2]string{
+	"matrix":  {"", ""},
+	"pmatrix": {"(", ")"},
+	"bmatrix": {"[", "]"},
+	"Bmatrix": {"{", "}"},
+	"vmatrix": {"|", "|"},
+	"Vmatrix": {"‖", "‖"},
+	"cases":   {"{", ""},
+	"aligned": {"", ""},
+	"align":   {"", ""},
+	"align*":  {"", ""},
+	"array":   {"", ""},
+}
+
+// tall holds the pieces delimiters spanning several lines are drawn with:
+// top, middle, bottom and the piece at the center of odd heights.
+var tall = map[string][4]string{
+	"(": {"⎛", "⎜", "⎝", "⎜"},
+	")": {"⎞", "⎟", "⎠", "⎟"},
+	"[": {"⎡", "⎢", "⎣", "⎢"},
+	"]": {"⎤", "⎥", "⎦", "⎥"},
+	"{": {"⎧", "⎪", "⎩", "⎨"},
+	"}": {"⎫", "⎪", "⎭", "⎬"},
+	"|": {"│", "│", "│", "│"},
+	"‖": {"‖", "‖", "‖", "‖"},
+}
@@ -0,0 +1,188 @@
+// Package mark provides goldmark extensions that parse ==highlighted==,
+// ^superscript^, ~subscript~ and ++inserted++ text.
+package mark
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Type is the type of marked text.
+type Type int
+
+const (
+	// Highlight is ==highlighted== text.
+	Highlight Type = iota
+	// Superscript is ^superscript^ text.
+	Superscript
+	// Subscript is ~subscript~ text.
+	Subscript
+	// Inserted is ++inserted++ text.
+	Inserted
+)
+
+// String returns the name of the type.
+func (t Type) String() string {
+	switch t {
+	case Highlight:
+		return "Highlight"
+	case Superscript:
+		return "Superscript"
+	case Subscript:
+		return "Subscript"
+	case Inserted:
+		return "Inserted"
+	}
+	return ""
+}
+
+// KindMark is the kind of marked text nodes.
+var KindMark = ast.NewNodeKind("Mark")
+
+// A Node is marked text.
+type Node struct {
+	ast.BaseInline
+
+	Mark Type
+}
+
+// Kind implements ast.Node.Kind.
+func (n *Node) Kind() ast.NodeKind {
+	return KindMark
+}
+
+// Dump implements ast.Node.Dump.
+func (n *Node) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Mark": n.Mark.String(),
+	}, nil)
+}
+
+// delimiterProcessor pairs the == and ++ delimiters around highlighted and
+// inserted text.
+type delimiterProcessor struct {
+	char byte
+	typ  Type
+}
+
+// IsDelimiter implements parser.DelimiterProcessor.IsDelimiter.
+func (p *delimiterProcessor) IsDelimiter(b byte) bool {
+	return b == p.char
+}
+
+// CanOpenCloser implements parser.DelimiterProcessor.CanOpenCloser.
+func (p *delimiterProcessor) CanOpenCloser(opener, closer *parser.Delimiter) bool {
+	return opener.Char == closer.Char
+}
+
+// OnMatch implements parser.DelimiterProcessor.OnMatch.
+func (p *delimiterProcessor) OnMatch(int) ast.Node {
+	return &Node{Mark: p.typ}
+}
+
+// delimiterParser parses text enclosed by two delimiter characters on each
+// side, which can hold other inline elements.
+type delimiterParser struct {
+	processor *delimiterProcessor
+}
+
+// Trigger implements parser.InlineParser.Trigger.
+func (p *delimiterParser) Trigger() []byte {
+	return []byte{p.processor.char}
+}
+
+// Parse implements parser.InlineParser.Parse.
+func (p *delimiterParser) Parse(_ ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	before := block.PrecendingCharacter()
+	line, segment := block.PeekLine()
+	node := parser.ScanDelimiter(line, before, 2, p.processor)
+	if node == nil || node.OriginalLength != 2 || before == rune(p.processor.char) {
+		return nil
+	}
+	node.Segment = segment.WithStop(segment.Start + node.OriginalLength)
+	block.Advance(node.OriginalLength)
+	pc.PushDelimiter(node)
+	return node
+}
+
+// scriptParser parses superscript and subscript text, which is enclosed by
+// a single delimiter character on each side and can't hold spaces.
+type scriptParser struct {
+	char byte
+	typ  Type
+}
+
+// Trigger implements parser.InlineParser.Trigger.
+func (p *scriptParser) Trigger() []byte {
+	return []byte{p.char}
+}
+
+// Parse implements parser.InlineParser.Parse.
+func (p *scriptParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	// runs of delimiters, like ~~strikethrough~~, are left to other parsers
+	if len(line) < 3 || line[1] == p.char || block.PrecendingCharacter() == rune(p.char) {
+		return nil
+	}
+
+	// the text is split around backslash escapes, which are dropped
+	var segments []text.Segment
+	start := 1
+	for i := 1; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			if util.IsPunct(line[i+1]) {
+				segments = append(segments, text.NewSegment(segment.Start+start, segment.Start+i))
+				start = i + 1
+			}
+			i++
+		case util.IsSpace(line[i]):
+			return nil
+		case line[i] == p.char:
+			if i+1 < len(line) && line[i+1] == p.char {
+				return nil
+			}
+			segments = append(segments, text.NewSegment(segment.Start+start, segment.Start+i))
+			n := &Node{Mark: p.typ}
+			for _, seg := range segments {
+				if !seg.IsEmpty() {
+					n.AppendChild(n, ast.NewTextSegment(seg))
+				}
+			}
+			block.Advance(i + 1)
+			return n
+		}
+	}
+	return nil
+}
+
+type extender struct {
+	typ Type
+}
+
+// New returns an extension that parses marked text of the given type.
+func New(t Type) goldmark.Extender {
+	return &extender{typ: t}
+}
+
+// Extend implements goldmark.Extender.Extend.
+func (e *extender) Extend(m goldmark.Markdown) {
+	var p parser.InlineParser
+	switch e.typ {
+	case Highlight:
+		p = &delimiterParser{processor: &delimiterProcessor{char: '=', typ: Highlight}}
+	case Inserted:
+		p = &delimiterParser{processor: &delimiterProcessor{char: '+', typ: Inserted}}
+	case Superscript:
+		p = &scriptParser{char: '^', typ: Superscript}
+	case Subscript:
+		p = &scriptParser{char: '~', typ: Subscript}
+	}
+	// subscripts are parsed before single tilde strikethrough
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		util.Prioritized(p, 450),
+	))
+}
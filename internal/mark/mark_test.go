@@ -0,0 +1,77 @@
+package mark_test
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/glamour/v2/internal/mark"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestParser(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"==mark==", "Highlight[Text]"},
+		{"==a **b**==", "Highlight[Text Emphasis]"},
+		{"a == b == c", "Text Text Text"},
+		{"===a===", "Text Text"},
+		{"++ins++", "Inserted[Text]"},
+		{"C++ and C++", "Text Text"},
+		{"2^10^", "Text Superscript[Text]"},
+		{"a^b c^", "Text Text"},
+		{"H~2~O", "Text Subscript[Text] Text"},
+		{"~~struck~~", "Strikethrough"},
+		{"~a b~", "Strikethrough"},
+		{`x^a\^b^`, "Text Superscript[Text Text]"},
+	}
+	md := goldmark.New(goldmark.WithExtensions(
+		extension.Strikethrough,
+		mark.New(mark.Highlight),
+		mark.New(mark.Inserted),
+		mark.New(mark.Superscript),
+		mark.New(mark.Subscript),
+	))
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			source := []byte(tc.in)
+			doc := md.Parser().Parse(text.NewReader(source))
+			if got := dump(doc.FirstChild()); got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParserEscapes(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(mark.New(mark.Superscript)))
+	source := []byte(`x^a\^b\c^`)
+	doc := md.Parser().Parse(text.NewReader(source))
+	n := doc.FirstChild().LastChild()
+	if _, ok := n.(*mark.Node); !ok {
+		t.Fatalf("expected a superscript, got %s", n.Kind())
+	}
+	var b strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		b.Write(c.(*ast.Text).Segment.Value(source))
+	}
+	if got, want := b.String(), `a^b\c`; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// dump describes the children of a node.
+func dump(n ast.Node) string {
+	var parts []string
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		s := c.Kind().String()
+		if m, ok := c.(*mark.Node); ok {
+			s = m.Mark.String() + "[" + dump(c) + "]"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,703 @@
+package mermaid
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Node labels are wrapped to these widths, narrower ones being tried when a
+// flowchart doesn't fit.
+var labelWidths = []int{30, 20, 12}
+
+type fcNode struct {
+	id    string
+	label string
+	shape shape
+	dummy bool
+	layer int
+}
+
+type fcEdge struct {
+	from, to   *fcNode
+	label      string
+	style      lineStyle
+	head, tail bool
+
+	// reversed edges point up or left; they're either drawn that way or
+	// close a cycle.
+	reversed bool
+	// path holds the nodes an edge passes through from its upper to its
+	// lower end, with a dummy node for every layer in between.
+	path []*fcNode
+}
+
+type flowchart struct {
+	horizontal bool
+	flipped    bool
+	nodes      []*fcNode
+	byID       map[string]*fcNode
+	edges      []*fcEdge
+	layers     [][]*fcNode
+}
+
+func renderFlowchart(lines []string, width int, cs charset) ([]string, error) {
+	f, err := parseFlowchart(lines)
+	if err != nil {
+		return nil, err
+	}
+	f.rank()
+
+	// fall back to drawing top-down if a left-right flowchart is too wide
+	directions := []bool{f.horizontal}
+	if f.horizontal {
+		directions = append(directions, false)
+	}
+	for _, horizontal := range directions {
+		for _, w := range labelWidths {
+			c := f.draw(horizontal, w, cs)
+			if c.width() <= width {
+				return c.lines(), nil
+			}
+		}
+	}
+	return nil, errTooWide
+}
+
+func parseFlowchart(lines []string) (*flowchart, error) {
+	f := &flowchart{byID: map[string]*fcNode{}}
+
+	header := splitStatements(lines[0])
+	fields := strings.Fields(header[0])
+	direction := "TD"
+	if len(fields) > 1 {
+		direction = fields[1]
+	}
+	switch direction {
+	case "TD", "TB":
+	case "BT":
+		f.flipped = true
+	case "LR":
+		f.horizontal = true
+	case "RL":
+		f.horizontal, f.flipped = true, true
+	default:
+		return nil, fmt.Errorf("%w: direction %s", errUnsupported, direction)
+	}
+
+	statements := header[1:]
+	for _, l := range lines[1:] {
+		statements = append(statements, splitStatements(l)...)
+	}
+	for _, s := range statements {
+		if err := f.statement(s); err != nil {
+			return nil, err
+		}
+	}
+	if len(f.nodes) == 0 {
+		return nil, errUnsupported
+	}
+	return f, nil
+}
+
+// splitStatements splits a line at semicolons that aren't quoted.
+func splitStatements(l string) []string {
+	var statements []string
+	quoted := false
+	start := 0
+	for i, r := range l {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ';' && !quoted:
+			statements = append(statements, l[start:i])
+			start = i + 1
+		}
+	}
+	statements = append(statements, l[start:])
+
+	var out []string
+	for _, s := range statements {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (f *flowchart) statement(s string) error {
+	switch strings.Fields(s)[0] {
+	case "classDef", "class", "style", "linkStyle", "click":
+		// styling and interaction don't apply to text
+		return nil
+	case "subgraph", "end", "direction":
+		return fmt.Errorf("%w: subgraphs", errUnsupported)
+	}
+
+	prev, i, err := f.parseNodes(s, 0)
+	if err != nil {
+		return err
+	}
+	for i < len(s) {
+		l, n, ok := parseLink(s[i:])
+		if !ok {
+			return fmt.Errorf("%w: %q", errUnsupported, s)
+		}
+		var next []*fcNode
+		next, i, err = f.parseNodes(s, i+n)
+		if err != nil {
+			return err
+		}
+		if !l.invisible {
+			for _, from := range prev {
+				for _, to := range next {
+					if from == to {
+						return fmt.Errorf("%w: self-loops", errUnsupported)
+					}
+					e := l.fcEdge
+					e.from, e.to = from, to
+					f.edges = append(f.edges, &e)
+				}
+			}
+		}
+		prev = next
+	}
+	return nil
+}
+
+var shapes = []struct {
+	open, close string
+	shape       shape
+}{
+	{"(((", ")))", round},
+	{"((", "))", round},
+	{"([", "])", round},
+	{"[(", ")]", round},
+	{"[[", "]]", rect},
+	{"{{", "}}", rhombus},
+	{"[/", "]", rect},
+	{"[\\", "]", rect},
+	{"(", ")", round},
+	{"[", "]", rect},
+	{"{", "}", rhombus},
+	{">", "]", rect},
+}
+
+// parseNodes parses nodes joined by &, returning them and the position after
+// them.
+func (f *flowchart) parseNodes(s string, i int) ([]*fcNode, int, error) {
+	var nodes []*fcNode
+	for {
+		n, j, err := f.parseNode(s, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		nodes = append(nodes, n)
+		i = skipSpace(s, j)
+		if i == len(s) || s[i] != '&' {
+			return nodes, i, nil
+		}
+		i++
+	}
+}
+
+func (f *flowchart) parseNode(s string, i int) (*fcNode, int, error) {
+	start := skipSpace(s, i)
+	i = skipID(s, start)
+	id := s[start:i]
+	if id == "" {
+		return nil, 0, fmt.Errorf("%w: %q", errUnsupported, s)
+	}
+
+	n, ok := f.byID[id]
+	if !ok {
+		n = &fcNode{id: id, label: id}
+		f.byID[id] = n
+		f.nodes = append(f.nodes, n)
+	}
+
+	for _, sh := range shapes {
+		if !strings.HasPrefix(s[i:], sh.open) {
+			continue
+		}
+		j := i + len(sh.open)
+		end := closing(s[j:], sh.close)
+		if end < 0 {
+			return nil, 0, fmt.Errorf("%w: %q", errUnsupported, s)
+		}
+		n.label = label(strings.TrimRight(s[j:j+end], `/\`))
+		n.shape = sh.shape
+		i = j + end + len(sh.close)
+		break
+	}
+
+	// class shorthand
+	if strings.HasPrefix(s[i:], ":::") {
+		i = skipID(s, i+3)
+	}
+	return n, i, nil
+}
+
+// skipID returns the position after the node id starting at i.
+func skipID(s string, i int) int {
+	for j, r := range s[i:] {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return i + j
+		}
+	}
+	return len(s)
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// closing returns the position of the closing delimiter of a node, skipping
+// quoted text, or -1.
+func closing(s, delim string) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(s[i:], delim):
+			return i
+		}
+	}
+	return -1
+}
+
+type link struct {
+	fcEdge
+	invisible bool
+}
+
+var (
+	textLinkRe = regexp.MustCompile(`^\s*(<?)(--|==|-\.)\s+(.*?)\s+(-{2,}|={2,}|\.+-)([>ox]?)`)
+	linkRe     = regexp.MustCompile(`^\s*(<?)(-{2,}|={2,}|-\.+-|~{3,})([>ox]?)(\s*\|([^|]*)\|)?`)
+)
+
+// parseLink parses a link between nodes, returning it and its length.
+func parseLink(s string) (link, int, bool) {
+	var l link
+	var m []string
+	var line string
+	if m = textLinkRe.FindStringSubmatch(s); m != nil {
+		line, l.label = m[2]+m[4], m[3]
+		l.tail, l.head = m[1] != "", m[5] != ""
+	} else if m = linkRe.FindStringSubmatch(s); m != nil {
+		line, l.label = m[2], m[5]
+		l.tail, l.head = m[1] != "", m[3] != ""
+	} else {
+		return l, 0, false
+	}
+
+	l.label = label(l.label)
+	switch {
+	case strings.HasPrefix(line, "~"):
+		l.invisible = true
+	case strings.Contains(line, "."):
+		l.style = dotted
+	case strings.Contains(line, "="):
+		l.style = thick
+	}
+	return l, len(m[0]), true
+}
+
+// rank assigns nodes to layers and orders them within their layers, adding
+// dummy nodes for edges that span several layers.
+func (f *flowchart) rank() {
+	// reverse edges that close cycles
+	out := map[*fcNode][]*fcEdge{}
+	for _, e := range f.edges {
+		out[e.from] = append(out[e.from], e)
+	}
+	state := map[*fcNode]int{}
+	var visit func(n *fcNode)
+	visit = func(n *fcNode) {
+		state[n] = 1
+		for _, e := range out[n] {
+			switch state[e.to] {
+			case 0:
+				visit(e.to)
+			case 1:
+				e.reversed = true
+			}
+		}
+		state[n] = 2
+	}
+	for _, n := range f.nodes {
+		if state[n] == 0 {
+			visit(n)
+		}
+	}
+
+	// longest path layering
+	in := map[*fcNode][]*fcNode{}
+	for _, e := range f.edges {
+		upper, lower := e.ends()
+		in[lower] = append(in[lower], upper)
+	}
+	done := map[*fcNode]bool{}
+	var layer func(n *fcNode) int
+	layer = func(n *fcNode) int {
+		if !done[n] {
+			done[n] = true
+			for _, u := range in[n] {
+				n.layer = max(n.layer, layer(u)+1)
+			}
+		}
+		return n.layer
+	}
+	last := 0
+	for _, n := range f.nodes {
+		last = max(last, layer(n))
+	}
+	if f.flipped {
+		for _, n := range f.nodes {
+			n.layer = last - n.layer
+		}
+		for _, e := range f.edges {
+			e.reversed = !e.reversed
+		}
+	}
+
+	f.layers = make([][]*fcNode, last+1)
+	for _, n := range f.nodes {
+		f.layers[n.layer] = append(f.layers[n.layer], n)
+	}
+	for _, e := range f.edges {
+		upper, lower := e.ends()
+		e.path = []*fcNode{upper}
+		for l := upper.layer + 1; l < lower.layer; l++ {
+			d := &fcNode{dummy: true, layer: l}
+			f.layers[l] = append(f.layers[l], d)
+			e.path = append(e.path, d)
+		}
+		e.path = append(e.path, lower)
+	}
+
+	f.order()
+}
+
+// ends returns the upper and lower end of an edge.
+func (e *fcEdge) ends() (*fcNode, *fcNode) {
+	if e.reversed {
+		return e.to, e.from
+	}
+	return e.from, e.to
+}
+
+// order orders the nodes in each layer to reduce crossing edges, moving
+// nodes toward the average position of their neighbors.
+func (f *flowchart) order() {
+	above := map[*fcNode][]*fcNode{}
+	below := map[*fcNode][]*fcNode{}
+	for _, e := range f.edges {
+		for i := 1; i < len(e.path); i++ {
+			below[e.path[i-1]] = append(below[e.path[i-1]], e.path[i])
+			above[e.path[i]] = append(above[e.path[i]], e.path[i-1])
+		}
+	}
+
+	pos := map[*fcNode]int{}
+	index := func(layer []*fcNode) {
+		for i, n := range layer {
+			pos[n] = i
+		}
+	}
+	for _, layer := range f.layers {
+		index(layer)
+	}
+	sortLayer := func(layer []*fcNode, neighbors map[*fcNode][]*fcNode) {
+		key := map[*fcNode]float64{}
+		for _, n := range layer {
+			key[n] = float64(pos[n])
+			if ns := neighbors[n]; len(ns) > 0 {
+				sum := 0
+				for _, m := range ns {
+					sum += pos[m]
+				}
+				key[n] = float64(sum) / float64(len(ns))
+			}
+		}
+		sort.SliceStable(layer, func(i, j int) bool {
+			return key[layer[i]] < key[layer[j]]
+		})
+		index(layer)
+	}
+	crossings := func() int {
+		n := 0
+		for _, layer := range f.layers {
+			var segments [][2]int
+			for _, u := range layer {
+				for _, v := range below[u] {
+					segments = append(segments, [2]int{pos[u], pos[v]})
+				}
+			}
+			for i, a := range segments {
+				for _, b := range segments[i+1:] {
+					if (a[0]-b[0])*(a[1]-b[1]) < 0 {
+						n++
+					}
+				}
+			}
+		}
+		return n
+	}
+
+	best := f.cloneLayers()
+	fewest := crossings()
+	for i := 0; i < 8 && fewest > 0; i++ {
+		if i%2 == 0 {
+			for _, layer := range f.layers[1:] {
+				sortLayer(layer, above)
+			}
+		} else {
+			for l := len(f.layers) - 2; l >= 0; l-- {
+				sortLayer(f.layers[l], below)
+			}
+		}
+		if n := crossings(); n < fewest {
+			best, fewest = f.cloneLayers(), n
+		}
+	}
+	f.layers = best
+}
+
+func (f *flowchart) cloneLayers() [][]*fcNode {
+	layers := make([][]*fcNode, len(f.layers))
+	for i, l := range f.layers {
+		layers[i] = slices.Clone(l)
+	}
+	return layers
+}
+
+// draw draws the flowchart top-down or left to right, with node labels
+// wrapped to the given width.
+//
+// Positions along the direction of the flowchart are ranks, positions
+// across it are orders. Each layer is followed by a gap holding a row or
+// column the edges leave their upper nodes through, tracks for the edges to
+// move across the flowchart on, room for labels and a row or column for
+// arrowheads.
+func (f *flowchart) draw(horizontal bool, labelWidth int, cs charset) *canvas {
+	type size struct {
+		lines []string
+		w, h  int
+	}
+	sizes := map[*fcNode]size{}
+	for _, n := range f.nodes {
+		lines, w := wrap(n.label, labelWidth)
+		sizes[n] = size{lines, w + 4, len(lines) + 2}
+	}
+	rankSize := func(n *fcNode) int {
+		if horizontal {
+			return sizes[n].w
+		}
+		return sizes[n].h
+	}
+	orderSize := func(n *fcNode) int {
+		switch {
+		case n.dummy:
+			return 1
+		case horizontal:
+			return sizes[n].h
+		}
+		return sizes[n].w
+	}
+
+	// edge labels are drawn where edges enter their lower nodes
+	labels := map[*fcNode][]string{}
+	for _, e := range f.edges {
+		if e.label != "" {
+			lower := e.path[len(e.path)-1]
+			labels[lower] = append(labels[lower], e.label)
+		}
+	}
+	labelText := func(n *fcNode) string {
+		return strings.Join(labels[n], ", ")
+	}
+
+	// orders
+	gap := 2
+	if horizontal {
+		gap = 1
+	}
+	slot := func(n *fcNode) int {
+		s := orderSize(n)
+		if !horizontal && len(labels[n]) > 0 {
+			s = max(s, ansi.StringWidth(labelText(n))+2)
+		}
+		return s
+	}
+	extent := make([]int, len(f.layers))
+	widest := 0
+	for l, layer := range f.layers {
+		for _, n := range layer {
+			extent[l] += slot(n) + gap
+		}
+		extent[l] -= gap
+		widest = max(widest, extent[l])
+	}
+	center := map[*fcNode]int{}
+	for l, layer := range f.layers {
+		start := widest/2 - extent[l]/2
+		for _, n := range layer {
+			center[n] = start + slot(n)/2
+			start += slot(n) + gap
+		}
+	}
+
+	// ranks
+	type gapLayout struct {
+		start, tracks, labels, end int
+		track                      map[*fcNode]int
+	}
+	thickness := make([]int, len(f.layers))
+	for l, layer := range f.layers {
+		for _, n := range layer {
+			if !n.dummy {
+				thickness[l] = max(thickness[l], rankSize(n))
+			}
+		}
+	}
+	ranks := make([]int, len(f.layers))
+	gaps := make([]gapLayout, len(f.layers)-1)
+	for l := range gaps {
+		g := gapLayout{start: ranks[l] + thickness[l], track: map[*fcNode]int{}}
+
+		// edges leaving the same node share a track
+		type span struct {
+			node   *fcNode
+			lo, hi int
+		}
+		var spans []span
+		seen := map[*fcNode]int{}
+		labelWidth := 0
+		for _, e := range f.edges {
+			for i := 1; i < len(e.path); i++ {
+				u, v := e.path[i-1], e.path[i]
+				if u.layer != l {
+					continue
+				}
+				if i == len(e.path)-1 && len(labels[v]) > 0 {
+					labelWidth = max(labelWidth, ansi.StringWidth(labelText(v)))
+				}
+				lo, hi := min(center[u], center[v]), max(center[u], center[v])
+				if j, ok := seen[u]; ok {
+					spans[j].lo, spans[j].hi = min(spans[j].lo, lo), max(spans[j].hi, hi)
+					continue
+				}
+				seen[u] = len(spans)
+				spans = append(spans, span{u, lo, hi})
+			}
+		}
+		sort.SliceStable(spans, func(i, j int) bool { return spans[i].lo < spans[j].lo })
+		var ends []int
+		for _, s := range spans {
+			if s.lo == s.hi {
+				continue
+			}
+			t := slices.IndexFunc(ends, func(end int) bool { return end+1 < s.lo })
+			if t < 0 {
+				t = len(ends)
+				ends = append(ends, 0)
+			}
+			ends[t] = s.hi
+			g.track[s.node] = t
+		}
+		g.tracks = max(len(ends), 1)
+
+		switch {
+		case horizontal:
+			g.labels = 1
+			if labelWidth > 0 {
+				g.labels = labelWidth + 4
+			}
+		case labelWidth > 0:
+			g.labels = 1
+		}
+		g.end = g.start + 1 + g.tracks + g.labels
+		gaps[l] = g
+		ranks[l+1] = g.end + 1
+	}
+
+	point := func(rank, order int) [2]int {
+		if horizontal {
+			return [2]int{rank, order}
+		}
+		return [2]int{order, rank}
+	}
+	forward, backward := down, up
+	if horizontal {
+		forward, backward = right, left
+	}
+
+	c := &canvas{cs: cs}
+	for l, layer := range f.layers {
+		for _, n := range layer {
+			if n.dummy {
+				continue
+			}
+			s := sizes[n]
+			if horizontal {
+				c.box(ranks[l], center[n]-s.h/2, thickness[l], s.h, n.shape, s.lines)
+			} else {
+				c.box(center[n]-s.w/2, ranks[l], s.w, thickness[l], n.shape, s.lines)
+			}
+		}
+	}
+
+	for _, e := range f.edges {
+		var points [][2]int
+		for i := 1; i < len(e.path); i++ {
+			u, v := e.path[i-1], e.path[i]
+			g := gaps[u.layer]
+			track := g.start + 1 + g.track[u]
+			points = append(points,
+				point(g.start, center[u]),
+				point(track, center[u]),
+				point(track, center[v]),
+				point(g.end, center[v]),
+			)
+		}
+		c.line(e.style, points...)
+
+		upper, lower := e.path[0], e.path[len(e.path)-1]
+		first, last := gaps[upper.layer], gaps[lower.layer-1]
+		head, tail := e.head, e.tail
+		if e.reversed {
+			head, tail = tail, head
+		}
+		if head {
+			p := point(last.end, center[lower])
+			c.text(p[0], p[1], cs.arrow(forward))
+		}
+		if tail {
+			p := point(first.start, center[upper])
+			c.text(p[0], p[1], cs.arrow(backward))
+		}
+
+		if e.label == "" || labels[lower][0] != e.label {
+			continue
+		}
+		text := labelText(lower)
+		if horizontal {
+			start := last.start + 1 + last.tracks
+			mid := start + last.labels/2
+			c.text(mid-ansi.StringWidth(text)/2-1, center[lower], " "+text+" ")
+		} else {
+			c.centered(center[lower], last.end-1, text)
+		}
+	}
+	return c
+}
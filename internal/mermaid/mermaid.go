@@ -0,0 +1,323 @@
+// Package mermaid draws Mermaid flowcharts and sequence diagrams as text.
+package mermaid
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var (
+	errUnsupported = errors.New("mermaid: unsupported diagram")
+	errTooWide     = errors.New("mermaid: diagram too wide")
+)
+
+// Render draws a Mermaid diagram, fitting it in the given number of columns.
+// Boxes are drawn with the border; lines and arrows are drawn with ASCII
+// characters if the border is made of them. It returns an error for diagram
+// types and syntax it doesn't support, and for diagrams that don't fit.
+func Render(src string, width int, border lipgloss.Border) ([]string, error) {
+	lines := statements(src)
+	if len(lines) == 0 {
+		return nil, errUnsupported
+	}
+	cs := charset{border: border, ascii: isASCII(border.TopLeft + border.Top + border.Left)}
+
+	switch strings.Fields(strings.ReplaceAll(lines[0], ";", " "))[0] {
+	case "graph", "flowchart":
+		return renderFlowchart(lines, width, cs)
+	case "sequenceDiagram":
+		return renderSequence(lines[1:], width, cs)
+	}
+	return nil, fmt.Errorf("%w: %s", errUnsupported, lines[0])
+}
+
+// statements returns the trimmed lines of a diagram, leaving out blank lines,
+// comments and front matter.
+func statements(src string) []string {
+	var lines []string
+	inFrontMatter := false
+	for i, l := range strings.Split(src, "\n") {
+		l = strings.TrimSpace(l)
+		switch {
+		case l == "---" && (i == 0 || inFrontMatter):
+			inFrontMatter = !inFrontMatter
+		case inFrontMatter, l == "", strings.HasPrefix(l, "%%"):
+		default:
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+var iconRe = regexp.MustCompile(`\bfa[bsr]?:fa-[\w-]+\s*`)
+
+// label cleans up the text of a node or message, removing quotes and icons
+// and turning <br> tags into line breaks.
+func label(s string) string {
+	s = strings.TrimSpace(iconRe.ReplaceAllString(s, ""))
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	for _, br := range []string{"<br>", "<br/>", "<br />"} {
+		s = strings.ReplaceAll(s, br, "\n")
+	}
+	return s
+}
+
+// wrap wraps text to the given width, returning its lines and their
+// maximum width.
+func wrap(s string, width int) ([]string, int) {
+	return textLines(ansi.Wrap(s, width, " -"))
+}
+
+// textLines splits text into lines, returning them and their maximum width.
+func textLines(s string) ([]string, int) {
+	lines := strings.Split(s, "\n")
+	w := 0
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+		w = max(w, ansi.StringWidth(lines[i]))
+	}
+	return lines, w
+}
+
+type charset struct {
+	border lipgloss.Border
+	ascii  bool
+}
+
+// arrow returns the arrowhead pointing in the given direction.
+func (cs charset) arrow(d dir) string {
+	heads := map[dir][2]string{
+		up:    {"▲", "^"},
+		down:  {"▼", "v"},
+		left:  {"◀", "<"},
+		right: {"▶", ">"},
+	}
+	if cs.ascii {
+		return heads[d][1]
+	}
+	return heads[d][0]
+}
+
+// dir is a set of directions a line leaves a cell in.
+type dir uint8
+
+const (
+	up dir = 1 << iota
+	down
+	left
+	right
+)
+
+type lineStyle uint8
+
+const (
+	solid lineStyle = iota
+	dotted
+	thick
+)
+
+type cell struct {
+	text  string
+	isSet bool
+	lines dir
+	style lineStyle
+}
+
+// A canvas is a grid of cells that lines and text are drawn on. Lines
+// crossing or meeting in a cell are joined.
+type canvas struct {
+	rows [][]cell
+	cs   charset
+}
+
+func (c *canvas) at(x, y int) *cell {
+	for len(c.rows) <= y {
+		c.rows = append(c.rows, nil)
+	}
+	for len(c.rows[y]) <= x {
+		c.rows[y] = append(c.rows[y], cell{})
+	}
+	return &c.rows[y][x]
+}
+
+func (c *canvas) width() int {
+	w := 0
+	for _, r := range c.rows {
+		w = max(w, len(r))
+	}
+	return w
+}
+
+// line draws a line through the given points, which have to be on a
+// horizontal or vertical line with the point before them.
+func (c *canvas) line(style lineStyle, points ...[2]int) {
+	for i := 1; i < len(points); i++ {
+		x0, y0 := points[i-1][0], points[i-1][1]
+		x1, y1 := points[i][0], points[i][1]
+		var d, back dir
+		switch {
+		case x1 > x0:
+			d, back = right, left
+		case x1 < x0:
+			d, back = left, right
+		case y1 > y0:
+			d, back = down, up
+		case y1 < y0:
+			d, back = up, down
+		default:
+			continue
+		}
+		for x, y := x0, y0; ; {
+			cl := c.at(x, y)
+			cl.style = style
+			if x != x0 || y != y0 {
+				cl.lines |= back
+			}
+			if x == x1 && y == y1 {
+				break
+			}
+			cl.lines |= d
+			switch d {
+			case right:
+				x++
+			case left:
+				x--
+			case down:
+				y++
+			case up:
+				y--
+			}
+		}
+	}
+}
+
+// text writes text starting at the given cell. Wide characters take up two
+// cells.
+func (c *canvas) text(x, y int, s string) {
+	for _, r := range s {
+		w := ansi.StringWidth(string(r))
+		if w == 0 && x > 0 {
+			c.at(x-1, y).text += string(r)
+			continue
+		}
+		cl := c.at(x, y)
+		cl.text, cl.isSet = string(r), true
+		for i := 1; i < w; i++ {
+			cl := c.at(x+i, y)
+			cl.text, cl.isSet = "", true
+		}
+		x += w
+	}
+}
+
+// centered writes lines of text centered on the given column.
+func (c *canvas) centered(x, y int, lines ...string) {
+	for i, l := range lines {
+		c.text(x-ansi.StringWidth(l)/2, y+i, l)
+	}
+}
+
+type shape int
+
+const (
+	rect shape = iota
+	round
+	rhombus
+)
+
+// box draws a box with lines of text centered in it.
+func (c *canvas) box(x, y, w, h int, s shape, lines []string) {
+	b := c.cs.border
+	tl, tr, bl, br := b.TopLeft, b.TopRight, b.BottomLeft, b.BottomRight
+	switch {
+	case s == round && !c.cs.ascii:
+		tl, tr, bl, br = "╭", "╮", "╰", "╯"
+	case s == rhombus && c.cs.ascii:
+		tl, tr, bl, br = "/", "\\", "\\", "/"
+	case s == rhombus:
+		tl, tr, bl, br = "╱", "╲", "╲", "╱"
+	}
+
+	c.text(x, y, tl+strings.Repeat(b.Top, w-2)+tr)
+	for i := 1; i < h-1; i++ {
+		c.text(x, y+i, b.Left+strings.Repeat(" ", w-2)+b.Right)
+	}
+	c.text(x, y+h-1, bl+strings.Repeat(b.Bottom, w-2)+br)
+
+	top := y + 1 + (h-2-len(lines))/2
+	c.centered(x+w/2, top, lines...)
+}
+
+func (c *canvas) lines() []string {
+	out := make([]string, len(c.rows))
+	for i, r := range c.rows {
+		var b strings.Builder
+		for _, cl := range r {
+			switch {
+			case cl.isSet:
+				b.WriteString(cl.text)
+			case cl.lines != 0:
+				b.WriteString(c.glyph(cl))
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		out[i] = strings.TrimRight(b.String(), " ")
+	}
+	return out
+}
+
+func (c *canvas) glyph(cl cell) string {
+	vertical := cl.lines&(left|right) == 0
+	horizontal := cl.lines&(up|down) == 0
+
+	if c.cs.ascii {
+		switch {
+		case vertical && cl.style == dotted:
+			return ":"
+		case vertical:
+			return "|"
+		case horizontal && cl.style == dotted:
+			return "."
+		case horizontal && cl.style == thick:
+			return "="
+		case horizontal:
+			return "-"
+		}
+		return "+"
+	}
+
+	switch {
+	case vertical:
+		return [...]string{"│", "┆", "┃"}[cl.style]
+	case horizontal:
+		return [...]string{"─", "┄", "━"}[cl.style]
+	}
+	return map[dir]string{
+		down | right:             "┌",
+		down | left:              "┐",
+		up | right:               "└",
+		up | left:                "┘",
+		up | down | right:        "├",
+		up | down | left:         "┤",
+		down | left | right:      "┬",
+		up | left | right:        "┴",
+		up | down | left | right: "┼",
+	}[cl.lines]
+}
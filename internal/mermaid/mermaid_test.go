@@ -0,0 +1,98 @@
+package mermaid_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"charm.land/glamour/v2/internal/mermaid"
+	"charm.land/lipgloss/v2"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		border lipgloss.Border
+		want   string
+	}{
+		{
+			"flowchart", "graph TD\nA --> B", lipgloss.NormalBorder(), `
+┌───┐
+│ A │
+└───┘
+  │
+  │
+  ▼
+┌───┐
+│ B │
+└───┘`,
+		},
+		{
+			"left to right", "flowchart LR; A[Start] -- go --> B((Stop))", lipgloss.NormalBorder(), `
+┌───────┐         ╭──────╮
+│ Start │─── go ─▶│ Stop │
+└───────┘         ╰──────╯`,
+		},
+		{
+			"fan-in", "graph TD\nA & B --> C", lipgloss.ASCIIBorder(), `
++---+  +---+
+| A |  | B |
++---+  +---+
+  |      |
+  +---+  |
+      +--+
+      v
+    +---+
+    | C |
+    +---+`,
+		},
+		{
+			"sequence", "sequenceDiagram\nA->>B: hi\nB-->>A: ok", lipgloss.NormalBorder(), `
+┌───┐  ┌───┐
+│ A │  │ B │
+└───┘  └───┘
+  │      │
+  │  hi  │
+  ├─────▶│
+  │  ok  │
+  │◀┄┄┄┄┄┤
+  │      │
+┌───┐  ┌───┐
+│ A │  │ B │
+└───┘  └───┘`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			lines, err := mermaid.Render(tc.src, 80, tc.border)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := strings.Split(strings.TrimPrefix(tc.want, "\n"), "\n")
+			if !slices.Equal(lines, want) {
+				t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(lines, "\n"))
+			}
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"pie title Pets\n\"Dogs\" : 386",
+		"graph TD\nsubgraph one\nA --> B\nend",
+		"graph TD\nA --> A",
+		"sequenceDiagram\nloop forever\nA->>B: hi",
+	} {
+		if _, err := mermaid.Render(src, 80, lipgloss.NormalBorder()); err == nil {
+			t.Errorf("expected an error for %q", src)
+		}
+	}
+
+	// diagrams that don't fit
+	_, err := mermaid.Render("graph LR\nA[a long label] --> B[another long label]", 10, lipgloss.NormalBorder())
+	if err == nil {
+		t.Errorf("expected an error for a diagram that doesn't fit")
+	}
+}
@@ -0,0 +1,367 @@
+package mermaid
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+type participant struct {
+	id    string
+	lines []string
+	w     int
+}
+
+type eventKind int
+
+const (
+	message eventKind = iota
+	note
+	blockStart
+	blockDivider
+	blockEnd
+)
+
+type event struct {
+	kind     eventKind
+	from, to int
+	text     string
+	style    lineStyle
+	head     string
+	side     string
+	depth    int
+}
+
+type sequence struct {
+	participants []*participant
+	byID         map[string]int
+	events       []event
+	autonumber   bool
+	depth        int
+}
+
+var (
+	participantRe = regexp.MustCompile(`^(?:participant|actor)\s+(.+?)(?:\s+as\s+(.+))?$`)
+	noteRe        = regexp.MustCompile(`^(?i:note)\s+(left of|right of|over)\s+([^,:]+?)(?:\s*,\s*([^:]+?))?\s*:\s*(.*)$`)
+	messageRe     = regexp.MustCompile(`^(.+?)\s*(-->>|->>|-->|->|--x|-x|--\)|-\))\s*[+-]?\s*(.+?)\s*(?::\s*(.*))?$`)
+)
+
+func renderSequence(lines []string, width int, cs charset) ([]string, error) {
+	s, err := parseSequence(lines)
+	if err != nil {
+		return nil, err
+	}
+	c := s.draw(cs)
+	if c.width() > width {
+		return nil, errTooWide
+	}
+	return c.lines(), nil
+}
+
+func parseSequence(lines []string) (*sequence, error) {
+	s := &sequence{byID: map[string]int{}}
+	var blocks []string
+	for _, l := range lines {
+		keyword, rest, _ := strings.Cut(l, " ")
+		rest = strings.TrimSpace(rest)
+		switch keyword {
+		case "autonumber":
+			s.autonumber = true
+			continue
+		case "activate", "deactivate", "title":
+			continue
+		case "loop", "alt", "opt", "par", "critical", "break", "rect":
+			blocks = append(blocks, keyword)
+			s.depth = max(s.depth, len(blocks))
+			s.events = append(s.events, event{kind: blockStart, side: keyword, text: rest, depth: len(blocks)})
+			continue
+		case "else", "and", "option":
+			if len(blocks) == 0 {
+				return nil, fmt.Errorf("%w: %q", errUnsupported, l)
+			}
+			s.events = append(s.events, event{kind: blockDivider, side: blocks[len(blocks)-1], text: rest, depth: len(blocks)})
+			continue
+		case "end":
+			if len(blocks) == 0 {
+				return nil, fmt.Errorf("%w: %q", errUnsupported, l)
+			}
+			s.events = append(s.events, event{kind: blockEnd, side: blocks[len(blocks)-1], depth: len(blocks)})
+			blocks = blocks[:len(blocks)-1]
+			continue
+		}
+
+		if m := participantRe.FindStringSubmatch(l); m != nil {
+			i := s.participant(m[1])
+			if m[2] != "" {
+				s.participants[i].setLabel(m[2])
+			}
+			continue
+		}
+		if m := noteRe.FindStringSubmatch(l); m != nil {
+			e := event{kind: note, side: strings.Fields(m[1])[0], text: label(m[4])}
+			e.from = s.participant(m[2])
+			e.to = e.from
+			if m[3] != "" {
+				e.to = s.participant(m[3])
+			}
+			if e.from > e.to {
+				e.from, e.to = e.to, e.from
+			}
+			s.events = append(s.events, e)
+			continue
+		}
+		if m := messageRe.FindStringSubmatch(l); m != nil {
+			e := event{kind: message, text: strings.ReplaceAll(label(m[4]), "\n", " ")}
+			e.from, e.to = s.participant(m[1]), s.participant(m[3])
+			if strings.HasPrefix(m[2], "--") {
+				e.style = dotted
+			}
+			switch {
+			case strings.HasSuffix(m[2], "x"):
+				e.head = "x"
+			case strings.HasSuffix(m[2], ">>"), strings.HasSuffix(m[2], ")"):
+				e.head = ">"
+			}
+			s.events = append(s.events, e)
+			continue
+		}
+		return nil, fmt.Errorf("%w: %q", errUnsupported, l)
+	}
+	if len(blocks) > 0 || len(s.participants) == 0 {
+		return nil, errUnsupported
+	}
+
+	if s.autonumber {
+		n := 0
+		for i, e := range s.events {
+			if e.kind == message {
+				n++
+				s.events[i].text = strings.TrimSpace(strconv.Itoa(n) + ". " + e.text)
+			}
+		}
+	}
+	return s, nil
+}
+
+// participant returns the index of a participant, adding it if it's new.
+func (s *sequence) participant(id string) int {
+	id = strings.TrimSpace(id)
+	if i, ok := s.byID[id]; ok {
+		return i
+	}
+	p := &participant{id: id}
+	p.setLabel(id)
+	s.byID[id] = len(s.participants)
+	s.participants = append(s.participants, p)
+	return len(s.participants) - 1
+}
+
+func (p *participant) setLabel(s string) {
+	lines, w := textLines(label(s))
+	p.lines, p.w = lines, w+4
+}
+
+// draw draws the sequence diagram. Participants are laid out from left to
+// right with their lifelines going down, spaced so that messages and notes
+// fit between them.
+func (s *sequence) draw(cs charset) *canvas {
+	n := len(s.participants)
+	ps := s.participants
+
+	// distances between the lifelines of neighboring participants
+	gaps := make([]int, n)
+	for i := 0; i < n-1; i++ {
+		gaps[i] = (ps[i].w-1)/2 + ps[i+1].w/2 + 3
+	}
+	leftExtent, rightExtent := ps[0].w/2, (ps[n-1].w-1)/2
+
+	type constraint struct{ lo, hi, d int }
+	var constraints []constraint
+	spread := func(lo, hi, d int) {
+		constraints = append(constraints, constraint{lo, hi, d})
+	}
+	for _, e := range s.events {
+		w := ansi.StringWidth(e.text)
+		switch {
+		case e.kind == message && e.from != e.to:
+			spread(min(e.from, e.to), max(e.from, e.to), w+4)
+		case e.kind == message && e.to < n-1:
+			spread(e.to, e.to+1, w+8)
+		case e.kind == message:
+			rightExtent = max(rightExtent, w+6)
+		case e.kind == note:
+			_, w := textLines(e.text)
+			bw := w + 4
+			switch {
+			case e.side == "over" && e.from != e.to:
+				spread(e.from, e.to, w-1)
+			case e.side == "right" && e.to < n-1:
+				spread(e.to, e.to+1, bw+4)
+			case e.side == "right":
+				rightExtent = max(rightExtent, bw+2)
+			case e.side == "left" && e.from > 0:
+				spread(e.from-1, e.from, bw+4)
+			case e.side == "left":
+				leftExtent = max(leftExtent, bw+2)
+			default:
+				if e.from > 0 {
+					spread(e.from-1, e.from, bw/2+3)
+				} else {
+					leftExtent = max(leftExtent, bw/2)
+				}
+				if e.to < n-1 {
+					spread(e.to, e.to+1, bw-bw/2+3)
+				} else {
+					rightExtent = max(rightExtent, bw-bw/2)
+				}
+			}
+		}
+	}
+	sort.SliceStable(constraints, func(i, j int) bool {
+		return constraints[i].hi-constraints[i].lo < constraints[j].hi-constraints[j].lo
+	})
+	for _, c := range constraints {
+		d := 0
+		for _, g := range gaps[c.lo:c.hi] {
+			d += g
+		}
+		if d < c.d {
+			gaps[c.hi-1] += c.d - d
+		}
+	}
+
+	margin := 2 * s.depth
+	centers := make([]int, n)
+	centers[0] = margin + leftExtent
+	for i := 1; i < n; i++ {
+		centers[i] = centers[i-1] + gaps[i-1]
+	}
+	width := centers[n-1] + rightExtent + 1 + margin
+	for _, e := range s.events {
+		if e.kind == blockStart {
+			inset := 2 * (e.depth - 1)
+			width = max(width, 2*inset+ansi.StringWidth(e.side+e.text)+8)
+		}
+	}
+
+	c := &canvas{cs: cs}
+	height := 0
+	for _, p := range ps {
+		height = max(height, len(p.lines)+2)
+	}
+	boxes := func(y int) {
+		for i, p := range ps {
+			c.box(centers[i]-p.w/2, y, p.w, height, rect, p.lines)
+		}
+	}
+
+	y := height + 1
+	var starts []int
+	frame := func(depth int) (int, int) {
+		inset := 2 * (depth - 1)
+		return inset, width - 1 - inset
+	}
+	tag := func(e event) string {
+		switch {
+		case e.kind == blockStart && e.text != "":
+			return " " + e.side + " [" + e.text + "] "
+		case e.kind == blockStart:
+			return " " + e.side + " "
+		case e.text != "":
+			return " [" + e.text + "] "
+		}
+		return ""
+	}
+	for _, e := range s.events {
+		switch e.kind {
+		case message:
+			from, to := centers[e.from], centers[e.to]
+			if from == to {
+				c.line(e.style, [2]int{from, y}, [2]int{from + 3, y}, [2]int{from + 3, y + 1}, [2]int{from + 1, y + 1})
+				if e.head != "" {
+					c.text(from+1, y+1, s.head(cs, e.head, left))
+				}
+				c.text(from+5, y, e.text)
+				y += 2
+				break
+			}
+			if e.text != "" {
+				c.centered((from+to+1)/2, y, e.text)
+				y++
+			}
+			d, end := right, to-1
+			if to < from {
+				d, end = left, to+1
+			}
+			c.line(e.style, [2]int{from, y}, [2]int{end, y})
+			if e.head != "" {
+				c.text(end, y, s.head(cs, e.head, d))
+			}
+			y++
+
+		case note:
+			lines, w := textLines(e.text)
+			bw, h := w+4, len(lines)+2
+			from, to := centers[e.from], centers[e.to]
+			switch {
+			case e.side == "right":
+				c.box(to+2, y, bw, h, rect, lines)
+			case e.side == "left":
+				c.box(from-1-bw, y, bw, h, rect, lines)
+			case e.from != e.to:
+				c.box(from-2, y, to-from+5, h, rect, lines)
+			default:
+				c.box(from-bw/2, y, bw, h, rect, lines)
+			}
+			y += h
+
+		case blockStart:
+			starts = append(starts, y)
+			if e.side != "rect" {
+				x0, x1 := frame(e.depth)
+				c.line(solid, [2]int{x0, y}, [2]int{x1, y})
+				c.text(x0+1, y, tag(e))
+			}
+			y++
+
+		case blockDivider:
+			x0, x1 := frame(e.depth)
+			c.line(dotted, [2]int{x0, y}, [2]int{x1, y})
+			c.text(x0+1, y, tag(e))
+			y++
+
+		case blockEnd:
+			start := starts[len(starts)-1]
+			starts = starts[:len(starts)-1]
+			if e.side == "rect" {
+				break
+			}
+			x0, x1 := frame(e.depth)
+			c.line(solid, [2]int{x0, start}, [2]int{x0, y}, [2]int{x1, y}, [2]int{x1, start})
+			y++
+		}
+	}
+
+	y++
+	for _, x := range centers {
+		c.line(solid, [2]int{x, height}, [2]int{x, y - 1})
+	}
+	boxes(0)
+	boxes(y)
+	return c
+}
+
+// head returns the arrowhead of a message.
+func (s *sequence) head(cs charset, head string, d dir) string {
+	switch {
+	case head == "x" && cs.ascii:
+		return "x"
+	case head == "x":
+		return "×"
+	}
+	return cs.arrow(d)
+}
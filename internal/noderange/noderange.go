@@ -0,0 +1,57 @@
+// Package noderange finds the source a goldmark node was parsed from.
+package noderange
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	astext "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Of returns the byte range of node and its children in the source. Nodes
+// that don't hold any source, like thematic breaks, have an empty range at 0.
+// Tables span their whole lines, including the pipes around their rows.
+func Of(node ast.Node, source []byte) (int, int) {
+	start, end := -1, -1
+	add := func(seg text.Segment) {
+		if start == -1 || seg.Start < start {
+			start = seg.Start
+		}
+		end = max(end, seg.Stop)
+	}
+
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if n.Type() == ast.TypeBlock {
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				add(lines.At(i))
+			}
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			add(n.Segment)
+		case *ast.HTMLBlock:
+			if n.HasClosure() {
+				add(n.ClosureLine)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	if start == -1 {
+		return 0, 0
+	}
+	if node.Kind() == astext.KindTable {
+		start = bytes.LastIndexByte(source[:start], '\n') + 1
+		if i := bytes.IndexByte(source[end:], '\n'); i >= 0 {
+			end += i
+		} else {
+			end = len(source)
+		}
+	}
+	return start, end
+}
@@ -0,0 +1,31 @@
+package noderange_test
+
+import (
+	"testing"
+
+	"charm.land/glamour/v2/internal/noderange"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Some *emphasized* text\n", "Some *emphasized* text"},
+		{"<div>\nhtml\n</div>\n", "<div>\nhtml\n</div>\n"},
+		{"> quote\n> more\n", "quote\n> more"},
+		{"---\n", ""},
+		{"| a | b |\n|---|---|\n| 1 | 2 |\n", "| a | b |\n|---|---|\n| 1 | 2 |"},
+	}
+	for _, tc := range tests {
+		source := []byte(tc.in)
+		doc := goldmark.New(goldmark.WithExtensions(extension.Table)).Parser().Parse(text.NewReader(source))
+		start, end := noderange.Of(doc.FirstChild(), source)
+		if got := string(source[start:end]); got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.in, tc.want, got)
+		}
+	}
+}
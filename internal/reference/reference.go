@@ -0,0 +1,181 @@
+// Package reference provides a goldmark extension that turns GitHub-style
+// references to issues, pull requests, users and commits into links.
+package reference
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// RepoContext is the repository references are resolved against.
+type RepoContext struct {
+	// Host is the host of the forge, e.g. "github.com", which is the default.
+	// It may include a scheme and a path.
+	Host string
+	// Owner and Repo are the repository #123 and commit SHAs refer to.
+	Owner string
+	Repo  string
+}
+
+// baseURL returns the URL of the host, without a trailing slash.
+func (c RepoContext) baseURL() string {
+	host := c.Host
+	if host == "" {
+		host = "github.com"
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	return strings.TrimSuffix(host, "/")
+}
+
+// KindReference is the kind of reference nodes.
+var KindReference = ast.NewNodeKind("Reference")
+
+// A Node is a reference to an issue, pull request, user or commit.
+type Node struct {
+	ast.BaseInline
+
+	// Label is the reference as it's displayed. Commit SHAs are shortened.
+	Label string
+	// URL is the URL the reference points to.
+	URL string
+}
+
+// Kind implements ast.Node.Kind.
+func (n *Node) Kind() ast.NodeKind {
+	return KindReference
+}
+
+// Dump implements ast.Node.Dump.
+func (n *Node) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Label": n.Label,
+		"URL":   n.URL,
+	}, nil)
+}
+
+var (
+	issueRef   = regexp.MustCompile(`^(?:([A-Za-z0-9-]+)/([A-Za-z0-9._-]+))?#([0-9]+)`)
+	mentionRef = regexp.MustCompile(`^@([A-Za-z0-9](?:[A-Za-z0-9]|-[A-Za-z0-9]){0,38})`)
+	commitRef  = regexp.MustCompile(`^[0-9a-f]{7,40}`)
+)
+
+const shortSHA = 7
+
+type referenceParser struct {
+	repo RepoContext
+}
+
+// Trigger implements parser.InlineParser.Trigger.
+func (p *referenceParser) Trigger() []byte {
+	// ' ' indicates any white spaces and a line head
+	return []byte{' ', '(', '#', '@'}
+}
+
+// Parse implements parser.InlineParser.Parse.
+func (p *referenceParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if pc.IsInLinkLabel() {
+		return nil
+	}
+	line, segment := block.PeekLine()
+	if len(line) == 0 {
+		return nil
+	}
+
+	consumes := 0
+	switch line[0] {
+	case ' ', '\t', '(':
+		consumes++
+		line = line[1:]
+	case '#', '@':
+		// references start at word boundaries
+		if c := block.PrecendingCharacter(); isWordChar(c) || c == '/' {
+			return nil
+		}
+	}
+
+	n, length := p.match(line)
+	if n == nil {
+		return nil
+	}
+	if consumes != 0 {
+		ast.MergeOrAppendTextSegment(parent, segment.WithStop(segment.Start+consumes))
+	}
+	block.Advance(consumes + length)
+	return n
+}
+
+// match returns the reference at the start of line along with its length.
+func (p *referenceParser) match(line []byte) (*Node, int) {
+	base := p.repo.baseURL()
+	hasRepo := p.repo.Owner != "" && p.repo.Repo != ""
+	end := func(m []int) bool {
+		return m != nil && (m[1] == len(line) || !isWordChar(rune(line[m[1]])))
+	}
+
+	if m := issueRef.FindSubmatchIndex(line); end(m) {
+		owner, repo := p.repo.Owner, p.repo.Repo
+		if m[2] >= 0 {
+			owner, repo = string(line[m[2]:m[3]]), string(line[m[4]:m[5]])
+		} else if !hasRepo {
+			return nil, 0
+		}
+		return &Node{
+			Label: string(line[:m[1]]),
+			URL:   base + "/" + owner + "/" + repo + "/issues/" + string(line[m[6]:m[7]]),
+		}, m[1]
+	}
+
+	if m := mentionRef.FindSubmatchIndex(line); end(m) && (m[1] == len(line) || line[m[1]] != '/') {
+		return &Node{
+			Label: string(line[:m[1]]),
+			URL:   base + "/" + string(line[m[2]:m[3]]),
+		}, m[1]
+	}
+
+	// only words mixing digits and letters are taken for commit SHAs
+	if m := commitRef.FindIndex(line); hasRepo && end(m) {
+		sha := string(line[:m[1]])
+		if strings.ContainsAny(sha, "0123456789") && strings.ContainsAny(sha, "abcdef") {
+			return &Node{
+				Label: sha[:shortSHA],
+				URL:   base + "/" + p.repo.Owner + "/" + p.repo.Repo + "/commit/" + sha,
+			}, m[1]
+		}
+	}
+	return nil, 0
+}
+
+// isWordChar reports whether c may be part of a word, so references can't
+// start or end next to it.
+func isWordChar(c rune) bool {
+	return c == '_' || c == '-' || c >= utf8.RuneSelf || util.IsAlphaNumeric(byte(c))
+}
+
+type extender struct {
+	repo RepoContext
+}
+
+// New returns an extension that turns references into links pointing to the
+// given repository.
+func New(repo RepoContext) goldmark.Extender {
+	return &extender{repo: repo}
+}
+
+// Extend implements goldmark.Extender.Extend.
+func (e *extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithInlineParsers(
+			// after linkify, so references never break up URLs
+			util.Prioritized(&referenceParser{repo: e.repo}, 1000),
+		),
+	)
+}
@@ -0,0 +1,78 @@
+package reference_test
+
+import (
+	"slices"
+	"testing"
+
+	"charm.land/glamour/v2/internal/reference"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestReferences(t *testing.T) {
+	repo := reference.RepoContext{Owner: "charmbracelet", Repo: "glamour"}
+
+	tests := []struct {
+		name     string
+		in       string
+		expected []string
+	}{
+		{"issue", "Fixes #123.", []string{"#123 https://github.com/charmbracelet/glamour/issues/123"}},
+		{"line head", "#123 is fixed", []string{"#123 https://github.com/charmbracelet/glamour/issues/123"}},
+		{"other repo", "See charmbracelet/lipgloss#42", []string{"charmbracelet/lipgloss#42 https://github.com/charmbracelet/lipgloss/issues/42"}},
+		{"mention", "Thanks @meowgorithm!", []string{"@meowgorithm https://github.com/meowgorithm"}},
+		{"commit", "Since 0123abcdef4567", []string{"0123abc https://github.com/charmbracelet/glamour/commit/0123abcdef4567"}},
+		{"parens", "(#1, @a-b)", []string{"#1 https://github.com/charmbracelet/glamour/issues/1", "@a-b https://github.com/a-b"}},
+		{"emphasis", "**#7**", []string{"#7 https://github.com/charmbracelet/glamour/issues/7"}},
+
+		{"code span", "`#123` and `@user`", nil},
+		{"code block", "```\n#123 @user\n```", nil},
+		{"link text", "[#123](https://example.com)", nil},
+		{"email", "mail user@example.com", nil},
+		{"hex email", "mail abc1234@example.com", nil},
+		{"url", "https://example.com/a#123", nil},
+		{"word", "a#123 b@user", nil},
+		{"team", "@org/team", nil},
+		{"hex word", "decade facade 1234567", nil},
+		{"heading anchor", "#123abc", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithExtensions(extension.GFM, reference.New(repo)))
+			doc := md.Parser().Parse(text.NewReader([]byte(tc.in)))
+
+			var refs []string
+			_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+				if ref, ok := n.(*reference.Node); ok && entering {
+					refs = append(refs, ref.Label+" "+ref.URL)
+				}
+				return ast.WalkContinue, nil
+			})
+			if !slices.Equal(refs, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, refs)
+			}
+		})
+	}
+}
+
+func TestReferencesHost(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(reference.New(reference.RepoContext{
+		Host: "https://git.example.com/",
+	})))
+	doc := md.Parser().Parse(text.NewReader([]byte("#1 @user a/b#2 0123abcdef")))
+
+	var refs []string
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if ref, ok := n.(*reference.Node); ok && entering {
+			refs = append(refs, ref.URL)
+		}
+		return ast.WalkContinue, nil
+	})
+	// #1 and SHAs need a repository
+	expected := []string{"https://git.example.com/user", "https://git.example.com/a/b/issues/2"}
+	if !slices.Equal(refs, expected) {
+		t.Errorf("expected %q, got %q", expected, refs)
+	}
+}
@@ -0,0 +1,101 @@
+// Package wikilink provides a goldmark extension that parses wiki-style
+// [[Page Name]] and [[Page Name|alias]] links.
+package wikilink
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindWikiLink is the kind of wiki link nodes.
+var KindWikiLink = ast.NewNodeKind("WikiLink")
+
+// A Node is a wiki link. Its child is the text of the alias, or of the
+// target if the link has no alias.
+type Node struct {
+	ast.BaseInline
+
+	Target   string
+	HasAlias bool
+}
+
+// Kind implements ast.Node.Kind.
+func (n *Node) Kind() ast.NodeKind {
+	return KindWikiLink
+}
+
+// Dump implements ast.Node.Dump.
+func (n *Node) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Target":   n.Target,
+		"HasAlias": strconv.FormatBool(n.HasAlias),
+	}, nil)
+}
+
+var (
+	openDelim  = []byte("[[")
+	closeDelim = []byte("]]")
+)
+
+type wikiLinkParser struct{}
+
+// Trigger implements parser.InlineParser.Trigger.
+func (p *wikiLinkParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+// Parse implements parser.InlineParser.Parse.
+func (p *wikiLinkParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	if !bytes.HasPrefix(line, openDelim) {
+		return nil
+	}
+	end := bytes.Index(line[len(openDelim):], closeDelim)
+	if end < 0 {
+		return nil
+	}
+	content := line[len(openDelim) : len(openDelim)+end]
+	if bytes.ContainsAny(content, "[]") {
+		return nil
+	}
+
+	start := segment.Start + len(openDelim)
+	target, display := content, text.NewSegment(start, start+len(content))
+	n := &Node{}
+	if i := bytes.IndexByte(content, '|'); i >= 0 {
+		target = content[:i]
+		display = text.NewSegment(start+i+1, start+len(content))
+		n.HasAlias = true
+	}
+	n.Target = string(util.TrimLeftSpace(util.TrimRightSpace(target)))
+	display = display.TrimLeftSpace(block.Source())
+	display = display.TrimRightSpace(block.Source())
+	if n.Target == "" || display.IsEmpty() {
+		return nil
+	}
+
+	n.AppendChild(n, ast.NewTextSegment(display))
+	block.Advance(len(openDelim) + end + len(closeDelim))
+	return n
+}
+
+type extender struct{}
+
+// New returns an extension that parses wiki links.
+func New() goldmark.Extender {
+	return &extender{}
+}
+
+// Extend implements goldmark.Extender.Extend.
+func (e *extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		// before links, which start with "[" too
+		util.Prioritized(&wikiLinkParser{}, 199),
+	))
+}
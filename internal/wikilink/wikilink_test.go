@@ -0,0 +1,58 @@
+package wikilink_test
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/glamour/v2/internal/wikilink"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestParser(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"[[Page Name]]", "WikiLink(Page Name)[Page Name]"},
+		{"[[ page | the alias ]]", "WikiLink(page|)[the alias]"},
+		{"see [[a#heading]].", "Text WikiLink(a#heading)[a#heading] Text"},
+		{"[[open", "Text"},
+		{"[[]]", "Text"},
+		{"[[a|]]", "Text"},
+		{"[[a [b]]]", "Text"},
+		{"[text](url)", "Link"},
+	}
+	md := goldmark.New(goldmark.WithExtensions(wikilink.New()))
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			source := []byte(tc.in)
+			doc := md.Parser().Parse(text.NewReader(source))
+			if got := dump(doc.FirstChild(), source); got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+// dump describes the children of a node. Runs of text nodes are described
+// once.
+func dump(n ast.Node, source []byte) string {
+	var parts []string
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		s := c.Kind().String()
+		if l, ok := c.(*wikilink.Node); ok {
+			target := l.Target
+			if l.HasAlias {
+				target += "|"
+			}
+			display := string(l.FirstChild().(*ast.Text).Segment.Value(source))
+			s += "(" + target + ")[" + display + "]"
+		}
+		if len(parts) > 0 && s == "Text" && parts[len(parts)-1] == s {
+			continue
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
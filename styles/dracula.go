@@ -79,6 +79,23 @@ var DraculaStyleConfig = ansi.StyleConfig{
 		Color:  stringPtr("#6272A4"),
 		Format: "\n--------\n",
 	},
+	Mark: ansi.StylePrimitive{
+		Color:           stringPtr("#282a36"),
+		BackgroundColor: stringPtr("#f1fa8c"),
+	},
+	Inserted: ansi.StylePrimitive{
+		Underline: boolPtr(true),
+	},
+	Superscript: ansi.StylePrimitive{
+		Prefix: "^",
+	},
+	Subscript: ansi.StylePrimitive{
+		Prefix: "_",
+	},
+	Abbreviation: ansi.StylePrimitive{
+		Underline:      boolPtr(true),
+		UnderlineStyle: "dotted",
+	},
 	Item: ansi.StylePrimitive{
 		BlockPrefix: "• ",
 	},
@@ -98,6 +115,9 @@ var DraculaStyleConfig = ansi.StyleConfig{
 	LinkText: ansi.StylePrimitive{
 		Color: stringPtr("#ff79c6"),
 	},
+	BrokenLink: ansi.StylePrimitive{
+		Color: stringPtr("#ff5555"),
+	},
 	Image: ansi.StylePrimitive{
 		Color:     stringPtr("#8be9fd"),
 		Underline: boolPtr(true),
@@ -111,6 +131,15 @@ var DraculaStyleConfig = ansi.StyleConfig{
 			Color: stringPtr("#50fa7b"),
 		},
 	},
+	Kbd: ansi.StylePrimitive{
+		Prefix:          "\u00a0",
+		Suffix:          "\u00a0",
+		Color:           stringPtr("#f8f8f2"),
+		BackgroundColor: stringPtr("#44475a"),
+	},
+	Underline: ansi.StylePrimitive{
+		Underline: boolPtr(true),
+	},
 	CodeBlock: ansi.StyleCodeBlock{
 		StyleBlock: ansi.StyleBlock{
 			StylePrimitive: ansi.StylePrimitive{
@@ -205,12 +234,43 @@ var DraculaStyleConfig = ansi.StyleConfig{
 			},
 		},
 	},
+	Math: ansi.StyleBlock{
+		StylePrimitive: ansi.StylePrimitive{
+			Color: stringPtr("#8be9fd"),
+		},
+		Margin: uintPtr(defaultMargin),
+	},
+	Diagram: ansi.StyleDiagram{
+		StyleBlock: ansi.StyleBlock{
+			Margin: uintPtr(defaultMargin),
+		},
+	},
 	Table: ansi.StyleTable{
 		StyleBlock: ansi.StyleBlock{
 			StylePrimitive: ansi.StylePrimitive{},
 		},
+		Header: ansi.StylePrimitive{
+			Color: stringPtr("#bd93f9"),
+			Bold:  boolPtr(true),
+		},
+		Stacked: ansi.StyleTableStacked{
+			Label: ansi.StylePrimitive{
+				Bold: boolPtr(true),
+			},
+		},
 	},
 	DefinitionDescription: ansi.StylePrimitive{
 		BlockPrefix: "\n🠶 ",
 	},
+	Details: ansi.StyleDetails{
+		StyleBlock: ansi.StyleBlock{
+			Indent: uintPtr(defaultDetailsIndent),
+		},
+		Summary: ansi.StylePrimitive{
+			Color: stringPtr("#bd93f9"),
+			Bold:  boolPtr(true),
+		},
+		Open:   "▼ ",
+		Closed: "▶ ",
+	},
 }
@@ -11,6 +11,7 @@ const (
 	defaultListIndent      = 2
 	defaultListLevelIndent = 4
 	defaultMargin          = 2
+	defaultDetailsIndent   = 2
 )
 
 // Default styles.
@@ -83,6 +84,9 @@ var (
 				Prefix: "###### ",
 			},
 		},
+		FrontMatter: ansi.StyleFrontMatter{
+			Border: "ascii",
+		},
 		Strikethrough: ansi.StylePrimitive{
 			BlockPrefix: "~~",
 			BlockSuffix: "~~",
@@ -98,6 +102,20 @@ var (
 		HorizontalRule: ansi.StylePrimitive{
 			Format: "\n--------\n",
 		},
+		Mark: ansi.StylePrimitive{
+			Prefix: "==",
+			Suffix: "==",
+		},
+		Inserted: ansi.StylePrimitive{
+			Prefix: "++",
+			Suffix: "++",
+		},
+		Superscript: ansi.StylePrimitive{
+			Prefix: "^",
+		},
+		Subscript: ansi.StylePrimitive{
+			Prefix: "_",
+		},
 		Item: ansi.StylePrimitive{
 			BlockPrefix: "• ",
 		},
@@ -111,18 +129,35 @@ var (
 		ImageText: ansi.StylePrimitive{
 			Format: "Image: {{.text}} →",
 		},
+		BrokenLink: ansi.StylePrimitive{
+			Suffix: " (broken)",
+		},
 		Code: ansi.StyleBlock{
 			StylePrimitive: ansi.StylePrimitive{
 				BlockPrefix: "`",
 				BlockSuffix: "`",
 			},
 		},
+		Kbd: ansi.StylePrimitive{
+			Prefix: "[",
+			Suffix: "]",
+		},
 		CodeBlock: ansi.StyleCodeBlock{
 			StyleBlock: ansi.StyleBlock{
 				Margin: uintPtr(defaultMargin),
 			},
 		},
+		Math: ansi.StyleBlock{
+			Margin: uintPtr(defaultMargin),
+		},
+		Diagram: ansi.StyleDiagram{
+			StyleBlock: ansi.StyleBlock{
+				Margin: uintPtr(defaultMargin),
+			},
+			Border: "ascii",
+		},
 		Table: ansi.StyleTable{
+			Border:          "ascii",
 			CenterSeparator: stringPtr("|"),
 			ColumnSeparator: stringPtr("|"),
 			RowSeparator:    stringPtr("-"),
@@ -130,6 +165,13 @@ var (
 		DefinitionDescription: ansi.StylePrimitive{
 			BlockPrefix: "\n* ",
 		},
+		Details: ansi.StyleDetails{
+			StyleBlock: ansi.StyleBlock{
+				Indent: uintPtr(defaultDetailsIndent),
+			},
+			Open:   "v ",
+			Closed: "> ",
+		},
 	}
 
 	// DarkStyleConfig is the default dark style.
@@ -193,6 +235,14 @@ var (
 				Bold:   boolPtr(false),
 			},
 		},
+		FrontMatter: ansi.StyleFrontMatter{
+			Key: ansi.StylePrimitive{
+				Color: stringPtr("39"),
+				Bold:  boolPtr(true),
+			},
+			Border:      "rounded",
+			BorderColor: stringPtr("240"),
+		},
 		Strikethrough: ansi.StylePrimitive{
 			CrossedOut: boolPtr(true),
 		},
@@ -206,6 +256,23 @@ var (
 			Color:  stringPtr("240"),
 			Format: "\n--------\n",
 		},
+		Mark: ansi.StylePrimitive{
+			Color:           stringPtr("234"),
+			BackgroundColor: stringPtr("220"),
+		},
+		Inserted: ansi.StylePrimitive{
+			Underline: boolPtr(true),
+		},
+		Superscript: ansi.StylePrimitive{
+			Prefix: "^",
+		},
+		Subscript: ansi.StylePrimitive{
+			Prefix: "_",
+		},
+		Abbreviation: ansi.StylePrimitive{
+			Underline:      boolPtr(true),
+			UnderlineStyle: "dotted",
+		},
 		Item: ansi.StylePrimitive{
 			BlockPrefix: "• ",
 		},
@@ -225,6 +292,12 @@ var (
 			Color: stringPtr("35"),
 			Bold:  boolPtr(true),
 		},
+		BrokenLink: ansi.StylePrimitive{
+			Color: stringPtr("203"),
+		},
+		Reference: ansi.StylePrimitive{
+			Color: stringPtr("39"),
+		},
 		Image: ansi.StylePrimitive{
 			Color:     stringPtr("212"),
 			Underline: boolPtr(true),
@@ -241,6 +314,15 @@ var (
 				BackgroundColor: stringPtr("236"),
 			},
 		},
+		Kbd: ansi.StylePrimitive{
+			Prefix:          "\u00a0",
+			Suffix:          "\u00a0",
+			Color:           stringPtr("252"),
+			BackgroundColor: stringPtr("238"),
+		},
+		Underline: ansi.StylePrimitive{
+			Underline: boolPtr(true),
+		},
 		CodeBlock: ansi.StyleCodeBlock{
 			StyleBlock: ansi.StyleBlock{
 				StylePrimitive: ansi.StylePrimitive{
@@ -332,14 +414,43 @@ var (
 				},
 			},
 		},
+		Math: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				Color: stringPtr("153"),
+			},
+			Margin: uintPtr(defaultMargin),
+		},
+		Diagram: ansi.StyleDiagram{
+			StyleBlock: ansi.StyleBlock{
+				Margin: uintPtr(defaultMargin),
+			},
+		},
 		Table: ansi.StyleTable{
 			StyleBlock: ansi.StyleBlock{
 				StylePrimitive: ansi.StylePrimitive{},
 			},
+			Header: ansi.StylePrimitive{
+				Bold: boolPtr(true),
+			},
+			Stacked: ansi.StyleTableStacked{
+				Label: ansi.StylePrimitive{
+					Bold: boolPtr(true),
+				},
+			},
 		},
 		DefinitionDescription: ansi.StylePrimitive{
 			BlockPrefix: "\n🠶 ",
 		},
+		Details: ansi.StyleDetails{
+			StyleBlock: ansi.StyleBlock{
+				Indent: uintPtr(defaultDetailsIndent),
+			},
+			Summary: ansi.StylePrimitive{
+				Bold: boolPtr(true),
+			},
+			Open:   "▼ ",
+			Closed: "▶ ",
+		},
 	}
 
 	// LightStyleConfig is the default light style.
@@ -402,6 +513,14 @@ var (
 				Bold:   boolPtr(false),
 			},
 		},
+		FrontMatter: ansi.StyleFrontMatter{
+			Key: ansi.StylePrimitive{
+				Color: stringPtr("27"),
+				Bold:  boolPtr(true),
+			},
+			Border:      "rounded",
+			BorderColor: stringPtr("250"),
+		},
 		Strikethrough: ansi.StylePrimitive{
 			CrossedOut: boolPtr(true),
 		},
@@ -415,6 +534,23 @@ var (
 			Color:  stringPtr("249"),
 			Format: "\n--------\n",
 		},
+		Mark: ansi.StylePrimitive{
+			Color:           stringPtr("235"),
+			BackgroundColor: stringPtr("228"),
+		},
+		Inserted: ansi.StylePrimitive{
+			Underline: boolPtr(true),
+		},
+		Superscript: ansi.StylePrimitive{
+			Prefix: "^",
+		},
+		Subscript: ansi.StylePrimitive{
+			Prefix: "_",
+		},
+		Abbreviation: ansi.StylePrimitive{
+			Underline:      boolPtr(true),
+			UnderlineStyle: "dotted",
+		},
 		Item: ansi.StylePrimitive{
 			BlockPrefix: "• ",
 		},
@@ -434,6 +570,12 @@ var (
 			Color: stringPtr("29"),
 			Bold:  boolPtr(true),
 		},
+		BrokenLink: ansi.StylePrimitive{
+			Color: stringPtr("160"),
+		},
+		Reference: ansi.StylePrimitive{
+			Color: stringPtr("25"),
+		},
 		Image: ansi.StylePrimitive{
 			Color:     stringPtr("205"),
 			Underline: boolPtr(true),
@@ -450,6 +592,15 @@ var (
 				BackgroundColor: stringPtr("254"),
 			},
 		},
+		Kbd: ansi.StylePrimitive{
+			Prefix:          "\u00a0",
+			Suffix:          "\u00a0",
+			Color:           stringPtr("235"),
+			BackgroundColor: stringPtr("254"),
+		},
+		Underline: ansi.StylePrimitive{
+			Underline: boolPtr(true),
+		},
 		CodeBlock: ansi.StyleCodeBlock{
 			StyleBlock: ansi.StyleBlock{
 				StylePrimitive: ansi.StylePrimitive{
@@ -541,14 +692,43 @@ var (
 				},
 			},
 		},
+		Math: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				Color: stringPtr("25"),
+			},
+			Margin: uintPtr(defaultMargin),
+		},
+		Diagram: ansi.StyleDiagram{
+			StyleBlock: ansi.StyleBlock{
+				Margin: uintPtr(defaultMargin),
+			},
+		},
 		Table: ansi.StyleTable{
 			StyleBlock: ansi.StyleBlock{
 				StylePrimitive: ansi.StylePrimitive{},
 			},
+			Header: ansi.StylePrimitive{
+				Bold: boolPtr(true),
+			},
+			Stacked: ansi.StyleTableStacked{
+				Label: ansi.StylePrimitive{
+					Bold: boolPtr(true),
+				},
+			},
 		},
 		DefinitionDescription: ansi.StylePrimitive{
 			BlockPrefix: "\n🠶 ",
 		},
+		Details: ansi.StyleDetails{
+			StyleBlock: ansi.StyleBlock{
+				Indent: uintPtr(defaultDetailsIndent),
+			},
+			Summary: ansi.StylePrimitive{
+				Bold: boolPtr(true),
+			},
+			Open:   "▼ ",
+			Closed: "▶ ",
+		},
 	}
 
 	// PinkStyleConfig is the default pink style.
@@ -603,6 +783,13 @@ var (
 				Bold:   boolPtr(false),
 			},
 		},
+		FrontMatter: ansi.StyleFrontMatter{
+			Key: ansi.StylePrimitive{
+				Color: stringPtr("212"),
+				Bold:  boolPtr(true),
+			},
+			Border: "rounded",
+		},
 		Text: ansi.StylePrimitive{},
 		Strikethrough: ansi.StylePrimitive{
 			CrossedOut: boolPtr(true),
@@ -617,6 +804,23 @@ var (
 			Color:  stringPtr("212"),
 			Format: "\n──────\n",
 		},
+		Mark: ansi.StylePrimitive{
+			Color:           stringPtr("234"),
+			BackgroundColor: stringPtr("212"),
+		},
+		Inserted: ansi.StylePrimitive{
+			Underline: boolPtr(true),
+		},
+		Superscript: ansi.StylePrimitive{
+			Prefix: "^",
+		},
+		Subscript: ansi.StylePrimitive{
+			Prefix: "_",
+		},
+		Abbreviation: ansi.StylePrimitive{
+			Underline:      boolPtr(true),
+			UnderlineStyle: "dotted",
+		},
 		Item: ansi.StylePrimitive{
 			BlockPrefix: "• ",
 		},
@@ -634,6 +838,9 @@ var (
 		LinkText: ansi.StylePrimitive{
 			Bold: boolPtr(true),
 		},
+		BrokenLink: ansi.StylePrimitive{
+			Color: stringPtr("203"),
+		},
 		Image: ansi.StylePrimitive{
 			Underline: boolPtr(true),
 		},
@@ -648,12 +855,52 @@ var (
 				Suffix:          "\u00a0", // Use non-breaking space to prevent hard breaks
 			},
 		},
-		Table:          ansi.StyleTable{},
+		Kbd: ansi.StylePrimitive{
+			Prefix:          "\u00a0",
+			Suffix:          "\u00a0",
+			Color:           stringPtr("212"),
+			BackgroundColor: stringPtr("238"),
+		},
+		Underline: ansi.StylePrimitive{
+			Underline: boolPtr(true),
+		},
+		Math: ansi.StyleBlock{
+			StylePrimitive: ansi.StylePrimitive{
+				Color: stringPtr("212"),
+			},
+			Margin: uintPtr(defaultMargin),
+		},
+		Diagram: ansi.StyleDiagram{
+			StyleBlock: ansi.StyleBlock{
+				Margin: uintPtr(defaultMargin),
+			},
+		},
+		Table: ansi.StyleTable{
+			Header: ansi.StylePrimitive{
+				Bold: boolPtr(true),
+			},
+			Stacked: ansi.StyleTableStacked{
+				Label: ansi.StylePrimitive{
+					Bold: boolPtr(true),
+				},
+			},
+		},
 		DefinitionList: ansi.StyleBlock{},
 		DefinitionTerm: ansi.StylePrimitive{},
 		DefinitionDescription: ansi.StylePrimitive{
 			BlockPrefix: "\n🠶 ",
 		},
+		Details: ansi.StyleDetails{
+			StyleBlock: ansi.StyleBlock{
+				Indent: uintPtr(defaultDetailsIndent),
+			},
+			Summary: ansi.StylePrimitive{
+				Color: stringPtr("212"),
+				Bold:  boolPtr(true),
+			},
+			Open:   "▼ ",
+			Closed: "▶ ",
+		},
 		HTMLBlock: ansi.StyleBlock{},
 		HTMLSpan:  ansi.StyleBlock{},
 	}
@@ -76,6 +76,23 @@ var TokyoNightStyleConfig = ansi.StyleConfig{
 		Color:  stringPtr("#565f89"),
 		Format: "\n--------\n",
 	},
+	Mark: ansi.StylePrimitive{
+		Color:           stringPtr("#1a1b26"),
+		BackgroundColor: stringPtr("#e0af68"),
+	},
+	Inserted: ansi.StylePrimitive{
+		Underline: boolPtr(true),
+	},
+	Superscript: ansi.StylePrimitive{
+		Prefix: "^",
+	},
+	Subscript: ansi.StylePrimitive{
+		Prefix: "_",
+	},
+	Abbreviation: ansi.StylePrimitive{
+		Underline:      boolPtr(true),
+		UnderlineStyle: "dotted",
+	},
 	Item: ansi.StylePrimitive{
 		BlockPrefix: "• ",
 	},
@@ -95,6 +112,9 @@ var TokyoNightStyleConfig = ansi.StyleConfig{
 	LinkText: ansi.StylePrimitive{
 		Color: stringPtr("#2ac3de"),
 	},
+	BrokenLink: ansi.StylePrimitive{
+		Color: stringPtr("#f7768e"),
+	},
 	Image: ansi.StylePrimitive{
 		Color:     stringPtr("#7aa2f7"),
 		Underline: boolPtr(true),
@@ -108,6 +128,15 @@ var TokyoNightStyleConfig = ansi.StyleConfig{
 			Color: stringPtr("#9ece6a"),
 		},
 	},
+	Kbd: ansi.StylePrimitive{
+		Prefix:          "\u00a0",
+		Suffix:          "\u00a0",
+		Color:           stringPtr("#c0caf5"),
+		BackgroundColor: stringPtr("#3b4261"),
+	},
+	Underline: ansi.StylePrimitive{
+		Underline: boolPtr(true),
+	},
 	CodeBlock: ansi.StyleCodeBlock{
 		StyleBlock: ansi.StyleBlock{
 			StylePrimitive: ansi.StylePrimitive{
@@ -198,12 +227,43 @@ var TokyoNightStyleConfig = ansi.StyleConfig{
 			},
 		},
 	},
+	Math: ansi.StyleBlock{
+		StylePrimitive: ansi.StylePrimitive{
+			Color: stringPtr("#7dcfff"),
+		},
+		Margin: uintPtr(defaultMargin),
+	},
+	Diagram: ansi.StyleDiagram{
+		StyleBlock: ansi.StyleBlock{
+			Margin: uintPtr(defaultMargin),
+		},
+	},
 	Table: ansi.StyleTable{
 		StyleBlock: ansi.StyleBlock{
 			StylePrimitive: ansi.StylePrimitive{},
 		},
+		Header: ansi.StylePrimitive{
+			Color: stringPtr("#7aa2f7"),
+			Bold:  boolPtr(true),
+		},
+		Stacked: ansi.StyleTableStacked{
+			Label: ansi.StylePrimitive{
+				Bold: boolPtr(true),
+			},
+		},
 	},
 	DefinitionDescription: ansi.StylePrimitive{
 		BlockPrefix: "\n🠶 ",
 	},
+	Details: ansi.StyleDetails{
+		StyleBlock: ansi.StyleBlock{
+			Indent: uintPtr(defaultDetailsIndent),
+		},
+		Summary: ansi.StylePrimitive{
+			Color: stringPtr("#7aa2f7"),
+			Bold:  boolPtr(true),
+		},
+		Open:   "▼ ",
+		Closed: "▶ ",
+	},
 }